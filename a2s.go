@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// source engine query
+
+var a2sInfoRequest = append([]byte("\xFF\xFF\xFF\xFFTSource Engine Query"), 0)
+
+const (
+	a2sInfoResponse = 0x49 // 'I'
+	a2sChallenge    = 0x41 // 'A'
+)
+
+// sourceQuery sends an A2S_INFO request over UDP and returns the player
+// counts along with the server name and current map. It needs no password
+// and works whether or not RCON is reachable.
+func sourceQuery(address string, timeout time.Duration) (playerCount, error) {
+	conn, err := net.DialTimeout("udp", address, timeout)
+	if err != nil {
+		return playerCount{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	req := a2sInfoRequest
+	buf := make([]byte, 1400)
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := conn.Write(req); err != nil {
+			return playerCount{}, err
+		}
+		n, err := conn.Read(buf)
+		if err != nil {
+			return playerCount{}, err
+		}
+		resp := buf[:n]
+		if len(resp) < 5 || !bytes.Equal(resp[:4], []byte{0xFF, 0xFF, 0xFF, 0xFF}) {
+			return playerCount{}, errors.New("unexpected A2S response header")
+		}
+
+		switch resp[4] {
+		case a2sChallenge:
+			// Newer servers answer with a challenge that must be echoed back.
+			if len(resp) < 9 {
+				return playerCount{}, errors.New("short A2S challenge")
+			}
+			req = append(append([]byte{}, a2sInfoRequest...), resp[5:9]...)
+		case a2sInfoResponse:
+			return parseA2SInfo(resp[5:])
+		default:
+			return playerCount{}, fmt.Errorf("unexpected A2S response type 0x%02x", resp[4])
+		}
+	}
+	return playerCount{}, errors.New("A2S challenge not accepted")
+}
+
+// parseA2SInfo decodes the body of an A2S_INFO response, after its header.
+func parseA2SInfo(b []byte) (playerCount, error) {
+	r := bytes.NewReader(b)
+	if _, err := r.ReadByte(); err != nil { // protocol version
+		return playerCount{}, err
+	}
+
+	var str [4]string // name, map, folder, game
+	for i := range str {
+		s, err := readCString(r)
+		if err != nil {
+			return playerCount{}, err
+		}
+		str[i] = s
+	}
+
+	var fixed struct {
+		AppID   uint16
+		Players uint8
+		Max     uint8
+	}
+	if err := binary.Read(r, binary.LittleEndian, &fixed); err != nil {
+		return playerCount{}, fmt.Errorf("short A2S_INFO response: %w", err)
+	}
+	return playerCount{
+		online:  int(fixed.Players),
+		max:     int(fixed.Max),
+		title:   str[0],
+		mapName: str[1],
+	}, nil
+}
+
+func readCString(r *bytes.Reader) (string, error) {
+	var b []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("short A2S_INFO response: %w", err)
+		}
+		if c == 0 {
+			return string(b), nil
+		}
+		b = append(b, c)
+	}
+}
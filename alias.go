@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// command aliases
+
+const aliasPrefix = "!"
+
+// aliasMap maps alias names to their commands. An alias is one command or,
+// as a list, a macro of several sent in order:
+//
+//	aliases:
+//	  day: time set day
+//	  setup: [gamemode creative, time set day, weather clear]
+type aliasMap map[string]commandList
+
+// expandAlias replaces a leading "!name" with the commands it is aliased
+// to, keeping any trailing arguments on the last one. Per-server aliases
+// take precedence over global ones. Input that isn't a known alias is
+// returned as the only command with ok=false.
+func expandAlias(input string, serverAliases, globalAliases aliasMap) (cmds []string, ok bool) {
+	if !strings.HasPrefix(input, aliasPrefix) {
+		return []string{input}, false
+	}
+	name, args, _ := strings.Cut(strings.TrimPrefix(input, aliasPrefix), " ")
+
+	expanded, found := serverAliases[name]
+	if !found {
+		expanded, found = globalAliases[name]
+	}
+	if !found || len(expanded) == 0 {
+		return []string{input}, false
+	}
+	cmds = append([]string(nil), expanded...)
+	if args != "" {
+		cmds[len(cmds)-1] += " " + args
+	}
+	return cmds, true
+}
+
+// aliasStep describes command i of an alias's expansion for the log, e.g.
+// "!setup → time set day (2/3)".
+func aliasStep(input string, cmds []string, i int) string {
+	if len(cmds) == 1 {
+		return fmt.Sprintf("%s → %s", input, cmds[0])
+	}
+	return fmt.Sprintf("%s → %s (%d/%d)", input, cmds[i], i+1, len(cmds))
+}
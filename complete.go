@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// command completion
+
+// loadCommands reads a commands_file or script: one command per line, with
+// blank lines and # comments ignored.
+func loadCommands(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cmds []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cmds = append(cmds, line)
+	}
+	return cmds, sc.Err()
+}
+
+// completion is an in-progress Tab completion: the text the user typed and
+// the commands it matched, with index pointing at the one in the input.
+type completion struct {
+	prefix  string
+	matches []string
+	index   int
+}
+
+// canComplete reports whether Tab should complete rather than move focus:
+// the input is focused, holds text and the server has a commands file.
+func (m *model) canComplete() bool {
+	if m.focus != focusInput || m.input.Value() == "" {
+		return false
+	}
+	s := m.activeServer()
+	return s != nil && len(s.commands) > 0
+}
+
+// complete fills the input with the next (dir 1) or previous (dir -1)
+// command matching what was typed before the first Tab.
+func (m *model) complete(dir int) {
+	s := m.activeServer()
+	if s == nil || len(s.commands) == 0 {
+		return
+	}
+
+	if m.completion == nil {
+		prefix := m.input.Value()
+		var matches []string
+		for _, c := range s.commands {
+			if strings.HasPrefix(strings.ToLower(c), strings.ToLower(prefix)) {
+				matches = append(matches, c)
+			}
+		}
+		if len(matches) == 0 {
+			m.setStatus("No completions")
+			return
+		}
+		m.completion = &completion{prefix: prefix, matches: matches, index: -1}
+		if dir < 0 {
+			m.completion.index = 0
+		}
+	}
+
+	c := m.completion
+	c.index = (c.index + dir + len(c.matches)) % len(c.matches)
+	m.input.SetValue(c.matches[c.index])
+	m.input.CursorEnd()
+}
+
+// completionRows is how many candidates the popup shows at once.
+const completionRows = 8
+
+// popup renders the candidates as a boxed list no wider than width, scrolled
+// so the current one is visible, with a position line when they don't all
+// fit.
+func (c *completion) popup(width int) string {
+	start := 0
+	if c.index >= completionRows {
+		start = c.index - completionRows + 1
+	}
+	end := min(start+completionRows, len(c.matches))
+	inner := atLeast(width-6, 1) // border, padding and the "> " marker
+	var lines []string
+	for i := start; i < end; i++ {
+		text := truncate(c.matches[i], inner)
+		if i == c.index {
+			lines = append(lines, activeCandidateStyle.Render("> "+text))
+		} else {
+			lines = append(lines, candidateStyle.Render("  "+text))
+		}
+	}
+	if len(c.matches) > completionRows {
+		lines = append(lines, candidateStyle.Render(fmt.Sprintf("  %d/%d", c.index+1, len(c.matches))))
+	}
+	return popupStyle.Render(strings.Join(lines, "\n"))
+}
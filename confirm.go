@@ -0,0 +1,36 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmation prompt
+
+// confirmPrompt is a yes/no question shown in the status bar. While it is
+// open, y runs yes and any other key dismisses it.
+type confirmPrompt struct {
+	question string
+	yes      func(m *model) tea.Cmd
+	no       func(m *model) // optional
+}
+
+func (m *model) confirm(question string, yes func(m *model) tea.Cmd, no func(m *model)) {
+	m.prompt = &confirmPrompt{question: question, yes: yes, no: no}
+}
+
+// answerPrompt closes the open prompt with the pressed key.
+func (m *model) answerPrompt(msg tea.KeyMsg) tea.Cmd {
+	p := m.prompt
+	m.prompt = nil
+	if k := msg.String(); k == "y" || k == "Y" {
+		return p.yes(m)
+	}
+	if p.no != nil {
+		p.no(m)
+	}
+	return nil
+}
+
+func (p *confirmPrompt) render() string {
+	return confirmStyle.Render(p.question + " [y/N]")
+}
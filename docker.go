@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// docker
+
+func (s serverConfig) usesCompose() bool {
+	return s.ComposeFile != "" && s.ComposeService != ""
+}
+
+// hasContainer reports whether docker actions can target this server.
+func (s serverConfig) hasContainer() bool {
+	return s.Container != "" || s.usesCompose()
+}
+
+// containerLabel names the container or compose service for log messages.
+func (s serverConfig) containerLabel() string {
+	if s.usesCompose() {
+		return s.ComposeService
+	}
+	return s.Container
+}
+
+// dockerArgs builds the docker CLI arguments for action, using
+// `docker compose` when the server is configured with a compose service.
+func dockerArgs(s serverConfig, action string) ([]string, error) {
+	if s.usesCompose() {
+		compose := []string{"compose", "-f", s.ComposeFile}
+		switch action {
+		case "start", "stop", "restart", "pause", "unpause":
+			return append(compose, action, s.ComposeService), nil
+		case "status":
+			return append(compose, "ps", "--all", "--format", "{{.State}}\t{{.Health}}", s.ComposeService), nil
+		case "logs":
+			return append(compose, "logs", "--follow", "--tail", "50", "--no-log-prefix", s.ComposeService), nil
+		}
+		if s.Container == "" {
+			return nil, fmt.Errorf("%s is not supported for compose services", action)
+		}
+	}
+
+	switch action {
+	case "start":
+		return []string{"start", s.Container}, nil
+	case "stop":
+		return []string{"stop", s.Container}, nil
+	case "restart":
+		return []string{"restart", s.Container}, nil
+	case "pause":
+		return []string{"pause", s.Container}, nil
+	case "unpause":
+		return []string{"unpause", s.Container}, nil
+	case "status":
+		return []string{"inspect", "--format", "{{.State.Status}}\t{{if .State.Health}}{{.State.Health.Status}}{{end}}\t{{.State.StartedAt}}", s.Container}, nil
+	case "stats":
+		return []string{"stats", "--no-stream", "--format", "{{.CPUPerc}} {{.MemUsage}}", s.Container}, nil
+	case "logs":
+		return []string{"logs", "--follow", "--tail", "50", s.Container}, nil
+	}
+	return nil, fmt.Errorf("unknown action: %s", action)
+}
+
+// containerRuntime is the docker-compatible CLI that manages s's container.
+func (s serverConfig) containerRuntime() string {
+	if s.runtime != "" {
+		return s.runtime
+	}
+	return "docker"
+}
+
+// runtimeMissing reports whether any server has a container to manage but
+// the container runtime isn't on the PATH to do it with, and which runtime
+// that is.
+func runtimeMissing(servers []serverConfig) (string, bool) {
+	for _, s := range servers {
+		if s.hasContainer() {
+			_, err := exec.LookPath(s.containerRuntime())
+			return s.containerRuntime(), err != nil
+		}
+	}
+	return "", false
+}
+
+// dockerCommand prepares a container runtime invocation for s, pointing it
+// at the server's docker_host when one is configured.
+func dockerCommand(ctx context.Context, s serverConfig, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, s.containerRuntime(), args...)
+	if s.DockerHost != "" {
+		cmd.Env = append(os.Environ(), "DOCKER_HOST="+s.DockerHost)
+	}
+	return cmd
+}
+
+func dockerAction(s serverConfig, action string) tea.Cmd {
+	return func() tea.Msg {
+		if !s.hasContainer() {
+			return dockerResultMsg{
+				serverName: s.Name,
+				action:     action,
+				err:        fmt.Errorf("no container configured"),
+			}
+		}
+
+		args, err := dockerArgs(s, action)
+		if err != nil {
+			return dockerResultMsg{
+				serverName: s.Name,
+				action:     action,
+				err:        err,
+			}
+		}
+
+		cmd := dockerCommand(context.Background(), s, args...)
+		output, err := cmd.CombinedOutput()
+
+		return dockerResultMsg{
+			serverName: s.Name,
+			action:     action,
+			output:     string(output),
+			err:        err,
+		}
+	}
+}
+
+// runDocker dispatches a docker action, counting it as in flight until its
+// dockerResultMsg arrives.
+func (m *model) runDocker(s serverConfig, action string) tea.Cmd {
+	return tea.Batch(m.sending(1), dockerAction(s, action))
+}
+
+// containerPollMsg carries the state docker reports for each container,
+// e.g. "running" or "exited". Containers docker couldn't be asked about are
+// missing.
+type containerPollMsg struct {
+	states map[string]containerState // keyed by server name
+}
+
+// pollContainers asks docker for the state of every configured container,
+// concurrently, without logging anything.
+func pollContainers(servers []serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		states := make(map[string]containerState)
+
+		for _, s := range servers {
+			args, err := dockerArgs(s, "status")
+			if !s.hasContainer() || err != nil {
+				continue
+			}
+			wg.Add(1)
+			go func(s serverConfig) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), s.dialTimeout())
+				defer cancel()
+				out, err := dockerCommand(ctx, s, args...).Output()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				states[s.Name] = parseContainerState(string(out))
+				mu.Unlock()
+			}(s)
+		}
+		wg.Wait()
+
+		return containerPollMsg{states: states}
+	}
+}
+
+// container state
+
+// containerState is what the status action reports: the container's state
+// and, for containers with a HEALTHCHECK, its health.
+type containerState struct {
+	status  string    // e.g. "running", "exited"
+	health  string    // "healthy", "unhealthy", "starting", or "" without a healthcheck
+	started time.Time // last start, zero if never started or not reported (compose)
+}
+
+func (cs containerState) String() string {
+	if cs.health == "" {
+		return cs.status
+	}
+	return fmt.Sprintf("%s (%s)", cs.status, cs.health)
+}
+
+// details lays the state out for the log, after the action's own label:
+// "running  Health: healthy  Uptime: 3h12m".
+func (cs containerState) details() string {
+	fields := []string{cs.status}
+	if cs.health != "" {
+		fields = append(fields, "Health: "+cs.health)
+	}
+	if cs.status == "running" && !cs.started.IsZero() {
+		fields = append(fields, "Uptime: "+formatUptime(time.Since(cs.started)))
+	}
+	return strings.Join(fields, "  ")
+}
+
+// formatUptime shortens d to its two largest units, e.g. "2d5h" or "3h12m".
+func formatUptime(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", d/time.Hour, d%time.Hour/time.Minute)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", max(0, d/time.Second))
+}
+
+// parseContainerState reads `status` output: tab-separated state, health
+// and start time, the last two possibly empty or missing. Space-separated
+// "running healthy", as older recordings have it, is read too.
+func parseContainerState(out string) containerState {
+	line, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	sep := " "
+	if strings.Contains(line, "\t") {
+		sep = "\t"
+	}
+	fields := strings.Split(strings.TrimSpace(line), sep)
+	cs := containerState{status: strings.ToLower(strings.TrimSpace(fields[0]))}
+	if len(fields) > 1 {
+		cs.health = strings.ToLower(strings.TrimSpace(fields[1]))
+	}
+	if len(fields) > 2 {
+		// Never-started containers report the zero time, which stays zero.
+		cs.started, _ = time.Parse(time.RFC3339Nano, strings.TrimSpace(fields[2]))
+	}
+	return cs
+}
+
+// style colors a state by its status, except that a failing healthcheck
+// wins over "running".
+func (cs containerState) style() lipgloss.Style {
+	t := activeTheme
+	c := t.Status
+	switch {
+	case cs.health == "unhealthy":
+		c = t.Error
+	case cs.health == "starting":
+		c = t.Warning
+	case cs.status == "running":
+		c = t.Success
+	case cs.status == "exited", cs.status == "dead":
+		c = t.Error
+	case cs.status == "paused":
+		c = t.Warning
+	case cs.status == "restarting":
+		c = t.Docker
+	}
+	return lipgloss.NewStyle().Foreground(c)
+}
+
+// badge renders the state for the status bar, e.g. "● running (healthy)".
+func (cs containerState) badge() string {
+	return cs.style().Render("● " + cs.String())
+}
+
+// setContainerState records the last known state of a server's container,
+// returning a notification if it just stopped on its own.
+func (m *model) setContainerState(name string, state containerState) tea.Cmd {
+	prev, known := m.containers[name]
+	m.containers[name] = state
+	if !known || prev != state {
+		m.rebuildList()
+	}
+	if prev.status == "running" && state.status == "exited" {
+		m.pushLogFor(name, logWarn, fmt.Sprintf("[%s] 🐳 Container exited", name))
+		return m.notify(fmt.Sprintf("🐳 **%s**: container exited", name))
+	}
+	return nil
+}
+
+// containerStats is a parsed `docker stats --no-stream` sample.
+type containerStats struct {
+	cpu string // e.g. "1.25%"
+	mem string // e.g. "512MiB / 2GiB"
+}
+
+func (st containerStats) String() string {
+	return fmt.Sprintf("CPU %s · MEM %s", st.cpu, st.mem)
+}
+
+func parseContainerStats(out string) (containerStats, error) {
+	cpu, mem, ok := strings.Cut(strings.TrimSpace(out), " ")
+	if !ok || !strings.HasSuffix(cpu, "%") {
+		return containerStats{}, fmt.Errorf("unexpected output %q", strings.TrimSpace(out))
+	}
+	return containerStats{cpu: cpu, mem: strings.TrimSpace(mem)}, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// address failover
+
+// addressList is a server's RCON endpoints in the order they are tried: the
+// primary first, then any backups. The config accepts one address or a list.
+type addressList []string
+
+func (a *addressList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*a = addressList{n.Value}
+		return nil
+	}
+	var addrs []string
+	if err := n.Decode(&addrs); err != nil {
+		return err
+	}
+	*a = addrs
+	return nil
+}
+
+func (a *addressList) UnmarshalTOML(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*a = addressList{v}
+	case []interface{}:
+		addrs := make(addressList, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected an address string, got %T", e)
+			}
+			addrs = append(addrs, s)
+		}
+		*a = addrs
+	default:
+		return fmt.Errorf("expected an address or a list of addresses, got %T", v)
+	}
+	return nil
+}
+
+// MarshalYAML writes a single address back as a plain string, the way it
+// is usually configured.
+func (a addressList) MarshalYAML() (interface{}, error) {
+	if len(a) == 1 {
+		return a[0], nil
+	}
+	return []string(a), nil
+}
+
+// primary is the address tried first, or "" when none is configured.
+func (a addressList) primary() string {
+	if len(a) == 0 {
+		return ""
+	}
+	return a[0]
+}
+
+// summary is the primary address with a count of the backups behind it.
+func (a addressList) summary() string {
+	if len(a) <= 1 {
+		return a.primary()
+	}
+	return fmt.Sprintf("%s (+%d backup)", a.primary(), len(a)-1)
+}
+
+// isBackup reports whether addr is one of s's backup addresses rather than
+// its primary.
+func (s serverConfig) isBackup(addr string) bool {
+	return addr != "" && addr != s.Address.primary()
+}
+
+// noteEndpoint logs a connection that had to fall back to a backup address
+// and keeps the list indicator in step with where name was last reached.
+func (m *model) noteEndpoint(name, addr string) {
+	s := m.serverByName(name)
+	if s == nil || addr == "" {
+		return
+	}
+	state := reachOnline
+	if s.isBackup(addr) {
+		state = reachBackup
+		m.pushLogFor(name, logWarn, fmt.Sprintf("[%s] ↪️ Primary %s unreachable, connected via backup %s", name, s.Address.primary(), addr))
+	}
+	if m.reach[name] != state {
+		m.reach[name] = state
+		m.rebuildList()
+	}
+}
+
+// failoverError is every address's dial error, in the order they were
+// tried, for when none of them connected.
+type failoverError []error
+
+func (e failoverError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e failoverError) Unwrap() []error { return e }
+
+// dial opens an authenticated connection to s, trying each of its addresses
+// in order until one connects. It returns the address it connected to.
+func dial(s serverConfig) (rconClient, string, error) {
+	if s.needsPassword() {
+		return nil, "", errNoPassword
+	}
+	if len(s.Address) == 1 {
+		conn, err := dialAddress(s, s.Address[0])
+		return conn, s.Address[0], err
+	}
+	var errs failoverError
+	for _, addr := range s.Address {
+		conn, err := dialAddress(s, addr)
+		if err == nil {
+			return conn, addr, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+	}
+	return nil, "", errs
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// first run
+
+const starterYAML = `# bubblecon config. Add one entry under servers for each server you manage;
+# config-example.yaml in the repository lists every option.
+
+show_timestamps: true
+
+servers:
+  - name: My Server
+    address: 127.0.0.1:25575 # host:port of the RCON listener
+    password: changeme       # or ${ENV_VAR}, file:/run/secrets/rcon_pw or exec:pass show rcon
+    type: minecraft          # minecraft, source, factorio, rust or generic
+    # container: mc          # docker container, for start/stop/restart/logs
+`
+
+const starterTOML = `# bubblecon config. Add one [[servers]] table for each server you manage;
+# config-example.toml in the repository lists every option.
+
+show_timestamps = true
+
+[[servers]]
+name = "My Server"
+address = "127.0.0.1:25575" # host:port of the RCON listener
+password = "changeme"       # or ${ENV_VAR}, file:/run/secrets/rcon_pw or exec:pass show rcon
+type = "minecraft"          # minecraft, source, factorio, rust or generic
+# container = "mc"          # docker container, for start/stop/restart/logs
+`
+
+// missingConfigError is a config file that doesn't exist, as opposed to a
+// commands_file or password file it names.
+type missingConfigError struct {
+	path string
+	err  error
+}
+
+func (e *missingConfigError) Error() string { return e.err.Error() }
+func (e *missingConfigError) Unwrap() error { return e.err }
+
+// missingConfig returns the config path err says doesn't exist, if that is
+// why loading failed rather than the file being unreadable or invalid.
+func missingConfig(err error) (string, bool) {
+	var missing *missingConfigError
+	if !errors.As(err, &missing) {
+		return "", false
+	}
+	return missing.path, true
+}
+
+// offerStarterConfig asks on the terminal whether to write a starter config
+// to path, and writes it if the answer is yes.
+func offerStarterConfig(path string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "Config file %s not found. Write an example config there? [y/N] ", path)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		return false, nil
+	}
+
+	starter := starterYAML
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		starter = starterTOML
+	}
+	// The file holds a password, so keep it private to the user.
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(starter), 0o600); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// game types
+
+const (
+	typeGeneric   = "generic"
+	typeMinecraft = "minecraft"
+	typeSource    = "source"
+	typeFactorio  = "factorio"
+	typeRust      = "rust"
+)
+
+const (
+	protocolTCP       = "tcp"       // Source RCON, spoken by most games
+	protocolWebSocket = "websocket" // Rust WebRCON
+)
+
+// gameProfile holds the per-game quirks selected by a server's type.
+type gameProfile struct {
+	protocol    string                                                           // default when the server sets none
+	formatCodes bool                                                             // responses carry § formatting codes
+	queryPort   string                                                           // default port for player queries when query_address is unset
+	rconPort    string                                                           // filled in when address has no port; "" means it must be given
+	keepalive   string                                                           // harmless command for keep-alive pings; "" means keepalive_command is required
+	multiPacket bool                                                             // responses can span packets; read to a sentinel instead of taking the first
+	query       func(address string, timeout time.Duration) (playerCount, error) // nil means no player query support
+}
+
+var gameProfiles = map[string]gameProfile{
+	typeGeneric:   {protocol: protocolTCP, query: minecraftPing},
+	typeMinecraft: {protocol: protocolTCP, formatCodes: true, queryPort: "25565", rconPort: "25575", keepalive: "seed", query: minecraftPing},
+	typeSource:    {protocol: protocolTCP, queryPort: "27015", rconPort: "27015", keepalive: "echo", multiPacket: true, query: sourceQuery},
+	typeFactorio:  {protocol: protocolTCP, rconPort: "27015", keepalive: "/version"},
+	typeRust:      {protocol: protocolWebSocket, rconPort: "28016", keepalive: "serverinfo"},
+}
+
+// normalizeType validates a configured type, defaulting to generic.
+func normalizeType(t string) (string, error) {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" {
+		return typeGeneric, nil
+	}
+	if _, ok := gameProfiles[t]; !ok {
+		known := make([]string, 0, len(gameProfiles))
+		for k := range gameProfiles {
+			known = append(known, k)
+		}
+		sort.Strings(known)
+		return "", fmt.Errorf("unknown type %q (want one of %s)", t, strings.Join(known, ", "))
+	}
+	return t, nil
+}
+
+// normalizeProtocol validates a configured protocol, defaulting to the one
+// the server's type uses.
+func (s serverConfig) normalizeProtocol() (string, error) {
+	p := strings.ToLower(strings.TrimSpace(s.Protocol))
+	switch p {
+	case "":
+		return s.profile().protocol, nil
+	case protocolTCP, protocolWebSocket:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown protocol %q (want %s or %s)", p, protocolTCP, protocolWebSocket)
+}
+
+func (s serverConfig) profile() gameProfile {
+	if p, ok := gameProfiles[s.Type]; ok {
+		return p
+	}
+	return gameProfiles[typeGeneric]
+}
+
+// queryAddress is where player counts are queried: query_address if set,
+// otherwise the RCON host on the game's default query port.
+func (s serverConfig) queryAddress() string {
+	if s.QueryAddress != "" {
+		return s.QueryAddress
+	}
+	port := s.profile().queryPort
+	if port == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(s.Address.primary())
+	if err != nil {
+		return ""
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// plainResponse strips the server type's formatting codes from a response.
+func (s serverConfig) plainResponse(resp string) string {
+	if s.profile().formatCodes {
+		resp = stripFormatting(resp)
+	}
+	return resp
+}
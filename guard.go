@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dangerous command guard
+
+// commandRule is one blocked_commands entry: a prefix such as "ban @a", or
+// a regular expression written between slashes, e.g. "/^op\s/".
+type commandRule struct {
+	raw    string
+	prefix string
+	re     *regexp.Regexp
+}
+
+func compileCommandRules(entries []string) ([]commandRule, error) {
+	rules := make([]commandRule, 0, len(entries))
+	for _, e := range entries {
+		if len(e) > 1 && strings.HasPrefix(e, "/") && strings.HasSuffix(e, "/") {
+			re, err := regexp.Compile(e[1 : len(e)-1])
+			if err != nil {
+				return nil, fmt.Errorf("blocked_commands: %w", err)
+			}
+			rules = append(rules, commandRule{raw: e, re: re})
+			continue
+		}
+		rules = append(rules, commandRule{raw: e, prefix: normalizeCommand(e)})
+	}
+	return rules, nil
+}
+
+// normalizeCommand lowercases cmd and drops the leading slash some players
+// type out of habit, so "/Stop" and "stop" are the same command.
+func normalizeCommand(cmd string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(cmd), "/"))
+}
+
+// matches reports whether cmd is caught by the rule. Prefixes match whole
+// words, so "stop" blocks "stop" and "stop now" but not "stopsound".
+func (r commandRule) matches(cmd string) bool {
+	if r.re != nil {
+		return r.re.MatchString(cmd)
+	}
+	c := normalizeCommand(cmd)
+	return strings.HasPrefix(c, r.prefix) && (len(c) == len(r.prefix) || c[len(r.prefix)] == ' ')
+}
+
+// blockedBy returns the first blocked_commands entry cmd matches.
+func (s serverConfig) blockedBy(cmd string) (string, bool) {
+	for _, r := range s.blocked {
+		if r.matches(cmd) {
+			return r.raw, true
+		}
+	}
+	return "", false
+}
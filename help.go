@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// help overlay
+
+// helpGroups is the order sections appear in the overlay.
+var helpGroups = []string{"General", "Input", "Server list", "Log", "Docker"}
+
+// fixedKeys are the bindings that aren't configurable, by help section.
+var fixedKeys = map[string][][2]string{
+	"General": {
+		{"Tab / Shift+Tab", "cycle focus: input, list, log"},
+	},
+	"Input": {
+		{"Up / Down", "command history"},
+		{"Tab / Shift+Tab", "complete from commands_file"},
+		{"Ctrl+A / Ctrl+E", "start / end of line"},
+		{"Ctrl+W / Ctrl+U", "delete word / to line start"},
+		{"!name", "expand an alias"},
+	},
+	"Server list": {
+		{"Up / Down", "move the selection"},
+		{"Enter", "activate server / toggle group"},
+		{"Space", "toggle the selected group"},
+		{"/", "jump to a server by name"},
+		{"Esc", "back to the input"},
+		{"Click", "activate server / toggle group"},
+	},
+	"Log": {
+		{"PgUp / PgDown", "scroll"},
+		{"Up / Down", "scroll (log focused)"},
+		{"Mouse wheel", "scroll"},
+		{"Esc", "back to the input"},
+	},
+}
+
+// helpView renders the full keybinding reference centred in the window.
+func (m model) helpView() string {
+	rows := make(map[string][][2]string, len(helpGroups))
+	for _, a := range keyActions {
+		if !m.keys[a.name].Enabled() {
+			continue
+		}
+		keys := m.keys[a.name].Keys()
+		labels := make([]string, len(keys))
+		for i, k := range keys {
+			labels[i] = keyLabel(k)
+		}
+		rows[a.group] = append(rows[a.group], [2]string{strings.Join(labels, " / "), a.desc})
+	}
+	for g, fixed := range fixedKeys {
+		rows[g] = append(append([][2]string(nil), fixed...), rows[g]...)
+	}
+
+	keyWidth := 0
+	for _, r := range rows {
+		for _, row := range r {
+			if w := lipgloss.Width(row[0]); w > keyWidth {
+				keyWidth = w
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render("Keybindings"))
+	for _, g := range helpGroups {
+		if len(rows[g]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n%s", helpTitleStyle.Render(g))
+		for _, row := range rows[g] {
+			key := helpKeyStyle.Width(keyWidth).Render(row[0])
+			fmt.Fprintf(&b, "\n  %s  %s", key, helpDescStyle.Render(row[1]))
+		}
+	}
+	b.WriteString("\n\n" + helpDescStyle.Render("Press any key to close"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpBoxStyle.Render(b.String()))
+}
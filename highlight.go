@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// highlights
+
+// highlightConfig is one highlights entry: text to pick out of log lines,
+// either a case-insensitive substring or a regular expression between
+// slashes, and the color to draw it in.
+type highlightConfig struct {
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	Color   string `yaml:"color" toml:"color"` // ANSI number ("9") or hex ("#ff5f87")
+}
+
+type highlight struct {
+	re    *regexp.Regexp
+	style lipgloss.Style
+}
+
+func compileHighlights(entries []highlightConfig) ([]highlight, error) {
+	hl := make([]highlight, 0, len(entries))
+	for i, e := range entries {
+		if e.Pattern == "" || e.Color == "" {
+			return nil, fmt.Errorf("highlights: entry %d needs both a pattern and a color", i+1)
+		}
+		re, err := compilePattern(e.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("highlights: %w", err)
+		}
+		hl = append(hl, highlight{re: re, style: lipgloss.NewStyle().Foreground(lipgloss.Color(e.Color)).Bold(true)})
+	}
+	return hl, nil
+}
+
+// compilePattern compiles a log pattern: a regular expression between
+// slashes, or else a case-insensitive substring.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+		return regexp.Compile(p[1 : len(p)-1])
+	}
+	return regexp.Compile("(?i)" + regexp.QuoteMeta(p))
+}
+
+// renderHighlighted styles text with base, except for the parts a highlight
+// matches. Where matches overlap, the earlier rule wins.
+func renderHighlighted(text string, base lipgloss.Style, hl []highlight) string {
+	if len(hl) == 0 {
+		return base.Render(text)
+	}
+	owner := make([]int, len(text)) // index+1 of the rule styling each byte, 0 for none
+	for i, h := range hl {
+		for _, loc := range h.re.FindAllStringIndex(text, -1) {
+			for j := loc[0]; j < loc[1]; j++ {
+				if owner[j] == 0 {
+					owner[j] = i + 1
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(text); {
+		end := start
+		for end < len(text) && owner[end] == owner[start] {
+			end++
+		}
+		style := base
+		if o := owner[start]; o > 0 {
+			style = hl[o-1].style
+		}
+		b.WriteString(style.Render(text[start:end]))
+		start = end
+	}
+	return b.String()
+}
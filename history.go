@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const maxPersistedHistory = 1000
+
+// command history
+
+// cmdHistory is a list of submitted commands with a recall cursor. A cursor
+// equal to len(entries) means "past the newest entry", i.e. a fresh prompt.
+type cmdHistory struct {
+	entries []string
+	cursor  int
+}
+
+// add records a submitted command, skipping consecutive duplicates, and
+// resets the cursor to the end.
+func (h *cmdHistory) add(cmd string) {
+	if n := len(h.entries); n == 0 || h.entries[n-1] != cmd {
+		h.entries = append(h.entries, cmd)
+	}
+	h.cursor = len(h.entries)
+}
+
+// prev moves the cursor to the previous command. ok is false at the oldest entry.
+func (h *cmdHistory) prev() (cmd string, ok bool) {
+	if h.cursor == 0 {
+		return "", false
+	}
+	h.cursor--
+	return h.entries[h.cursor], true
+}
+
+// next moves the cursor to the next command, returning "" once it walks
+// past the newest entry.
+func (h *cmdHistory) next() string {
+	if h.cursor >= len(h.entries)-1 {
+		h.cursor = len(h.entries)
+		return ""
+	}
+	h.cursor++
+	return h.entries[h.cursor]
+}
+
+// persistence
+
+// configDir returns $XDG_CONFIG_HOME/bubblecon, or ~/.config/bubblecon
+// when that isn't set. Per-user state and profiles are kept there.
+func configDir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "bubblecon"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "bubblecon"), nil
+}
+
+// The history file groups commands by server under "[server name]" header
+// lines, e.g.
+//
+//	[Survival]
+//	save-all
+//	whitelist add alice
+//
+// Commands that start with "[" or `\` are written with a `\` in front, so
+// they can't be mistaken for a header.
+
+// loadHistory reads the persisted per-server histories. A missing or
+// unreadable file yields empty histories rather than an error.
+func loadHistory() map[string]*cmdHistory {
+	histories := make(map[string]*cmdHistory)
+
+	dir, err := configDir()
+	if err != nil {
+		return histories
+	}
+	f, err := os.Open(filepath.Join(dir, "history"))
+	if err != nil {
+		return histories
+	}
+	defer f.Close()
+
+	var cur *cmdHistory
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := line[1 : len(line)-1]
+			if cur = histories[name]; cur == nil {
+				cur = &cmdHistory{}
+				histories[name] = cur
+			}
+			continue
+		}
+		if line != "" && cur != nil {
+			cur.entries = append(cur.entries, strings.TrimPrefix(line, `\`))
+		}
+	}
+	for _, h := range histories {
+		if len(h.entries) > maxPersistedHistory {
+			h.entries = h.entries[len(h.entries)-maxPersistedHistory:]
+		}
+		h.cursor = len(h.entries)
+	}
+	return histories
+}
+
+// saveHistory writes the most recent commands of every server to the history file.
+func saveHistory(histories map[string]*cmdHistory) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(histories))
+	for name := range histories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		entries := histories[name].entries
+		if len(entries) == 0 {
+			continue
+		}
+		if len(entries) > maxPersistedHistory {
+			entries = entries[len(entries)-maxPersistedHistory:]
+		}
+		fmt.Fprintf(&b, "[%s]\n", name)
+		for _, e := range entries {
+			if strings.HasPrefix(e, "[") || strings.HasPrefix(e, `\`) {
+				b.WriteByte('\\')
+			}
+			b.WriteString(e)
+			b.WriteByte('\n')
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, "history"), []byte(b.String()), 0o600)
+}
+
+// loadActiveServer returns the server that was active when bubblecon last
+// quit, or "" if none was saved.
+func loadActiveServer() string {
+	dir, err := configDir()
+	if err != nil {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "active_server"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// saveActiveServer remembers name as the server to start on next time.
+func saveActiveServer(name string) error {
+	if name == "" {
+		return nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "active_server"), []byte(name+"\n"), 0o600)
+}
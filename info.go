@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// server info overlay
+
+const maskedPassword = "••••••"
+
+// serverInfo is the open connection-details overlay for one server. The
+// DNS lookup fills in resolved once it answers.
+type serverInfo struct {
+	serverName string
+	resolving  bool
+	resolved   []string
+	resolveErr error
+}
+
+// infoResolvedMsg is the DNS answer for an open info overlay.
+type infoResolvedMsg struct {
+	serverName string
+	addrs      []string
+	err        error
+}
+
+// openInfo shows the active server's details and starts looking up its
+// address.
+func (m *model) openInfo() tea.Cmd {
+	s := m.activeServer()
+	if s == nil {
+		return nil
+	}
+	m.info = &serverInfo{serverName: s.Name, resolving: true}
+	return resolveServer(*s)
+}
+
+// resolveServer looks up the host of s's address. The lookup is local, so
+// it may differ from what a proxy resolves.
+func resolveServer(s serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		host, _, err := net.SplitHostPort(s.Address.primary())
+		if err != nil {
+			return infoResolvedMsg{serverName: s.Name, err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.dialTimeout())
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		return infoResolvedMsg{serverName: s.Name, addrs: addrs, err: err}
+	}
+}
+
+// masked returns s with its secrets hidden, for display.
+func (s serverConfig) masked() serverConfig {
+	if s.Password != "" {
+		s.Password = maskedPassword
+	}
+	if u, err := url.Parse(s.Proxy); err == nil && s.Proxy != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), maskedPassword)
+			s.Proxy = u.String()
+		}
+	}
+	return s
+}
+
+// infoView renders the open overlay centred in the window: how the active
+// server is reached, then its config with the password masked.
+func (m model) infoView() string {
+	in := m.info
+	s := m.serverByName(in.serverName)
+	if s == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render(s.Name))
+	row := func(k, v string) {
+		fmt.Fprintf(&b, "\n  %s  %s", helpKeyStyle.Width(14).Render(k), helpDescStyle.Render(v))
+	}
+	row("Address", strings.Join(s.Address, ", "))
+	switch {
+	case in.resolving:
+		row("Resolves to", "looking up...")
+	case in.resolveErr != nil:
+		row("Resolves to", fmt.Sprintf("lookup failed: %v", in.resolveErr))
+	default:
+		row("Resolves to", strings.Join(in.resolved, ", "))
+	}
+	row("Protocol", s.Protocol)
+	conn := "none"
+	if addr := m.pool.endpoint(s.Name); addr != "" {
+		conn = "open to " + addr
+		if s.isBackup(addr) {
+			conn += " (backup)"
+		}
+	}
+	row("Pooled conn", conn)
+	last := "never"
+	if t, ok := m.lastOK[s.Name]; ok {
+		last = fmt.Sprintf("%s (%s ago)", t.Format("15:04:05"), time.Since(t).Round(time.Second))
+	}
+	row("Last success", last)
+
+	b.WriteString("\n\n" + helpTitleStyle.Render("Config"))
+	var out strings.Builder
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(s.masked()); err != nil {
+		b.WriteString("\n  " + helpDescStyle.Render(err.Error()))
+	} else {
+		for _, line := range strings.Split(strings.TrimRight(out.String(), "\n"), "\n") {
+			b.WriteString("\n  " + helpDescStyle.Render(line))
+		}
+	}
+	b.WriteString("\n\n" + helpDescStyle.Render("Press any key to close"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpBoxStyle.Render(b.String()))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keep-alive
+
+type keepaliveTickMsg struct{ serverName string }
+
+// keepaliveMsg reports one keep-alive. sent is false when there was no idle
+// connection to ping.
+type keepaliveMsg struct {
+	serverName string
+	sent       bool
+	err        error
+}
+
+func keepaliveTick(s serverConfig) tea.Cmd {
+	return tea.Tick(s.Keepalive, func(time.Time) tea.Msg {
+		return keepaliveTickMsg{serverName: s.Name}
+	})
+}
+
+// startKeepalives arms the first keep-alive of every server that has one.
+func startKeepalives(servers []serverConfig) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, s := range servers {
+		if s.Keepalive > 0 {
+			cmds = append(cmds, keepaliveTick(s))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// keepaliveCommand is the command pinged on s's idle connection: its
+// keepalive_command, or a harmless one for its game type.
+func (s serverConfig) keepaliveCommand() string {
+	if s.KeepaliveCommand != "" {
+		return s.KeepaliveCommand
+	}
+	return s.profile().keepalive
+}
+
+func sendKeepalive(pool *connPool, s serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		sent, err := pool.ping(s, s.keepaliveCommand())
+		return keepaliveMsg{serverName: s.Name, sent: sent, err: err}
+	}
+}
+
+// logKeepalive notes a keep-alive in the server's log; only failures show
+// outside verbose logging.
+func (m *model) logKeepalive(msg keepaliveMsg) {
+	switch {
+	case msg.err != nil:
+		m.pushLogFor(msg.serverName, logWarn, fmt.Sprintf("[%s] ⚠️ Keep-alive failed, reconnecting on the next command: %v", msg.serverName, msg.err))
+	case msg.sent:
+		m.pushLogFor(msg.serverName, logDebug, fmt.Sprintf("[%s] 💓 Keep-alive sent", msg.serverName))
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keybindings
+
+// keyAction describes one rebindable action: its name in the config's
+// keybindings section, its default keys, its description and the help
+// section it is listed under. Actions marked footer also appear in the
+// compact footer.
+type keyAction struct {
+	name   string
+	keys   string
+	desc   string
+	group  string
+	footer bool
+}
+
+// keyActions lists every rebindable action in display order.
+var keyActions = []keyAction{
+	{"switch", "ctrl+o", "next server", "General", true},
+	{"help", "?", "help", "General", true},
+	{"quit", "ctrl+c", "quit", "General", true},
+	{"reconnect", "ctrl+n", "reconnect to server", "General", false},
+	{"cancel", "esc", "cancel pending commands", "General", false},
+	{"reload", "f5", "reload the config", "General", false},
+	{"info", "i", "connection details (list or log focused)", "General", false},
+	{"send", "enter", "send", "Input", false},
+	{"dryrun", "ctrl+p", "toggle dry-run", "Input", false},
+	{"palette", "ctrl+g", "quick commands", "Input", false},
+	{"templates", "alt+t", "fill in a command template", "Input", false},
+	{"start", "ctrl+s", "start container", "Docker", true},
+	{"stop", "ctrl+x", "stop container", "Docker", true},
+	{"restart", "ctrl+r", "restart container", "Docker", true},
+	{"pause", "alt+p", "pause container", "Docker", false},
+	{"unpause", "alt+u", "unpause container", "Docker", false},
+	{"status", "ctrl+d", "container status", "Docker", false},
+	{"stats", "ctrl+t", "container stats", "Docker", false},
+	{"logs", "ctrl+l", "follow container logs, per server", "Docker", false},
+	{"export", "ctrl+e", "export log to a file", "Log", false},
+	{"expand", "alt+e", "show the last cut-off response in full", "Log", false},
+	{"bottom", "G", "jump to the newest line (log focused)", "Log", false},
+	{"clear", "ctrl+k", "clear the log", "Log", false},
+}
+
+// keyMap holds the bindings Update matches against, keyed by action name.
+type keyMap map[string]key.Binding
+
+// newKeyMap builds the bindings from the config's keybindings section. Each
+// value is one key or a comma-separated list ("ctrl+s, f5"); unmapped actions
+// keep their defaults. sendKey is the older send_key setting, used when the
+// section doesn't rebind send. Unknown action names and values that name no
+// key at all, like ",", are returned as warnings; the latter keep the default.
+func newKeyMap(overrides map[string]string, sendKey string) (keyMap, []string) {
+	km := make(keyMap, len(keyActions))
+	var warnings []string
+	for _, a := range keyActions {
+		spec := a.keys
+		if a.name == "send" && sendKey != "" {
+			spec = sendKey
+		}
+		if v, ok := overrides[a.name]; ok && strings.TrimSpace(v) != "" {
+			spec = v
+		}
+		keys := splitKeys(spec)
+		if len(keys) == 0 {
+			warnings = append(warnings, fmt.Sprintf("keybinding %q for %s names no key, keeping %s", spec, a.name, a.keys))
+			keys = splitKeys(a.keys)
+		}
+		km[a.name] = key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys[0]), a.desc))
+	}
+
+	for name := range overrides {
+		if _, ok := km[name]; !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown keybinding action %q", name))
+		}
+	}
+	sort.Strings(warnings)
+	return km, warnings
+}
+
+// matches reports whether msg triggers the named action.
+func (km keyMap) matches(msg tea.KeyMsg, action string) bool {
+	b, ok := km[action]
+	return ok && key.Matches(msg, b)
+}
+
+// disableGroup turns off every action in a help section, so its keys are
+// neither matched nor listed.
+func (km keyMap) disableGroup(group string) {
+	for _, a := range keyActions {
+		if a.group == group {
+			b := km[a.name]
+			b.SetEnabled(false)
+			km[a.name] = b
+		}
+	}
+}
+
+// label returns the display form of the first key bound to action.
+func (km keyMap) label(action string) string {
+	return km[action].Help().Key
+}
+
+// footer renders the common bindings as the one-line help shown under the
+// panes; the rest are listed in the help overlay.
+func (km keyMap) footer() string {
+	parts := []string{"[Tab] focus"}
+	for _, a := range keyActions {
+		if !a.footer || !km[a.name].Enabled() {
+			continue
+		}
+		h := km[a.name].Help()
+		desc := h.Desc
+		if a.group == "Docker" {
+			desc = a.name // the footer has no room for "container"
+		}
+		parts = append(parts, fmt.Sprintf("[%s] %s", h.Key, desc))
+	}
+	return " " + strings.Join(parts, " | ")
+}
+
+func splitKeys(spec string) []string {
+	var keys []string
+	for _, k := range strings.Split(spec, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// keyLabel turns a bubbletea key string into its display form, e.g.
+// "ctrl+o" → "Ctrl+O" and "alt+enter" → "Alt+Enter".
+func keyLabel(k string) string {
+	parts := strings.Split(k, "+")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		if len(p) == 1 {
+			parts[i] = strings.ToUpper(p)
+		} else {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
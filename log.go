@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultLogBufferLines = 500
+
+// log buffer
+
+type logKind int
+
+const (
+	logInfo logKind = iota
+	logCommand
+	logResponse
+	logDocker
+	logWarn
+	logError
+	logDebug // connection diagnostics, only kept at log_level verbose
+)
+
+// logLevel is how chatty the log is, set with log_level.
+type logLevel int
+
+const (
+	levelQuiet logLevel = iota
+	levelNormal
+	levelVerbose
+)
+
+var logLevels = map[string]logLevel{
+	"":        levelNormal,
+	"quiet":   levelQuiet,
+	"normal":  levelNormal,
+	"verbose": levelVerbose,
+}
+
+// level is the lowest log_level that keeps lines of this kind: quiet drops
+// informational chatter but never commands, responses or problems.
+func (k logKind) level() logLevel {
+	switch k {
+	case logInfo:
+		return levelNormal
+	case logDebug:
+		return levelVerbose
+	}
+	return levelQuiet
+}
+
+// logEntry is one line of the log pane. Text is stored unstyled; styling is
+// applied when rendering so exports stay plain.
+type logEntry struct {
+	stamp string // HH:MM:SS, empty unless timestamps are enabled
+	text  string
+	kind  logKind
+	codes bool   // text may contain Minecraft § formatting codes
+	full  string // the whole response when text was cut to max_response_lines
+	seq   uint64 // arrival order across every server's log
+	tail  bool   // a line from the server's followed container log
+}
+
+// plain returns the entry as exported text, timestamp included. Formatting
+// codes are kept only if raw is set.
+func (e logEntry) plain(raw bool) string {
+	text := e.text
+	if e.full != "" {
+		text = e.full
+	}
+	if e.codes && !raw {
+		text = stripFormatting(text)
+	}
+	if e.stamp == "" {
+		return text
+	}
+	return e.stamp + " " + text
+}
+
+// render styles the entry for the log pane, turning formatting codes into
+// colors if translate is set and dropping them otherwise. Highlights apply
+// unless the line's own colors are being shown.
+func (e logEntry) render(translate bool, hl []highlight) string {
+	var line string
+	switch {
+	case e.codes && translate:
+		line = renderFormatting(e.text, logStyles[e.kind])
+	case e.codes:
+		line = renderHighlighted(stripFormatting(e.text), logStyles[e.kind], hl)
+	default:
+		line = renderHighlighted(e.text, logStyles[e.kind], hl)
+	}
+	if e.stamp == "" {
+		return line
+	}
+	return timestampStyle.Render(e.stamp) + " " + line
+}
+
+// responseText formats a response so it reads as belonging to cmd, e.g.
+// "[Survival] < [save-all] Saved the game", with any further lines of a
+// multi-line response indented beneath it.
+func responseText(server, cmd, out string) string {
+	if out == "" {
+		out = "(no response)"
+	}
+	out = strings.ReplaceAll(strings.TrimRight(out, "\n"), "\n", "\n    ")
+	return fmt.Sprintf("[%s] < [%s] %s", server, cmd, out)
+}
+
+// truncateResponse cuts a response longer than max_response_lines down to
+// its first lines and a marker saying how to see the rest. It returns the
+// text to show and, if anything was cut, the whole response.
+func (m *model) truncateResponse(text string) (shown, full string) {
+	lines := strings.Split(text, "\n")
+	if m.maxRespLines <= 0 || len(lines) <= m.maxRespLines {
+		return text, ""
+	}
+	more := len(lines) - m.maxRespLines
+	marker := fmt.Sprintf("    ... (%d more lines, press %s to view full)", more, m.keys.label("expand"))
+	return strings.Join(append(lines[:m.maxRespLines:m.maxRespLines], marker), "\n"), text
+}
+
+// expandResponse puts the newest cut-off response in the active log back
+// in full, reporting whether there was one.
+func (m *model) expandResponse() bool {
+	buf := m.logs[m.activeName]
+	for i := len(buf) - 1; i >= 0; i-- {
+		if buf[i].full != "" {
+			buf[i].text, buf[i].full = buf[i].full, ""
+			m.refreshLog()
+			return true
+		}
+	}
+	return false
+}
+
+// pushLog appends a line to the active server's log.
+func (m *model) pushLog(kind logKind, line string) {
+	m.pushLogFor(m.activeName, kind, line)
+}
+
+// pushLogFor appends a line to the named server's log, which is only
+// re-rendered if it is the one currently on screen. Each server's buffer
+// keeps its newest logLimit lines. Lines below the configured log_level are
+// dropped here, so they never reach the buffer, the log file or exports.
+func (m *model) pushLogFor(server string, kind logKind, line string) {
+	m.appendLog(server, logEntry{text: line, kind: kind})
+}
+
+// pushLogLine appends a line from server's followed container log. It stays
+// in that server's buffer like any other line; logLines shows it alongside
+// the other followed containers' lines.
+func (m *model) pushLogLine(server, line string) {
+	m.appendLog(server, logEntry{text: line, kind: logDocker, tail: true})
+}
+
+func (m *model) appendLog(server string, e logEntry) {
+	kind, line := e.kind, e.text
+	if kind.level() > m.logLevel {
+		return
+	}
+	m.logSeq++
+	e.seq = m.logSeq
+	if kind == logResponse {
+		e.text, e.full = m.truncateResponse(line)
+		if s := m.serverByName(server); s != nil {
+			e.codes = s.profile().formatCodes
+		}
+	}
+	if m.timestamps {
+		e.stamp = time.Now().Format("15:04:05")
+	}
+	// Watches and the log file see the whole response, cut or not.
+	text := line
+	if e.codes {
+		text = stripFormatting(text)
+	}
+	// The user's own commands aren't news to them.
+	if kind != logCommand {
+		m.checkWatches(server, text)
+	}
+	var fileErr error
+	if m.logFile != nil {
+		if fileErr = m.logFile.write(text); fileErr != nil {
+			m.logFile.close()
+			m.logFile = nil
+		}
+	}
+	buf := append(m.logs[server], e)
+	if len(buf) > m.logLimit {
+		// Reslicing leaves the dropped entries in the backing array only
+		// until the next append outgrows it and copies the live ones, so a
+		// buffer never holds much more than logLimit entries.
+		buf = buf[len(buf)-m.logLimit:]
+	}
+	m.logs[server] = buf
+	if server == m.activeName || e.tail && m.mergingLogs() {
+		m.refreshLog()
+	}
+	if fileErr != nil {
+		m.pushLogFor(server, logError, fmt.Sprintf("❌ Log file disabled: %v", fileErr))
+	}
+}
+
+// logDial records, at log_level verbose, the connection a command had to
+// open before it could be sent.
+func (m *model) logDial(msg rconResultMsg) {
+	s := m.serverByName(msg.serverName)
+	if s == nil || msg.dialed == 0 {
+		return
+	}
+	m.pushLogFor(s.Name, logDebug, fmt.Sprintf("[%s] 🔌 Dialed %s over %s, dial and auth took %dms", s.Name, msg.endpoint, s.Protocol, msg.dialed.Milliseconds()))
+}
+
+// exportLog writes the active server's log as plain text to a timestamped file in the
+// working directory and returns its path. With export_format "raw", Minecraft
+// formatting codes are written as received.
+func (m *model) exportLog() (string, error) {
+	path := fmt.Sprintf("bubblecon-log-%s.txt", time.Now().Format("20060102-150405"))
+	var b strings.Builder
+	for _, e := range m.logs[m.activeName] {
+		b.WriteString(e.plain(m.exportRaw))
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// refreshLog re-renders the active server's log into the viewport,
+// following new output only if the user hasn't scrolled up to read history.
+// Entries are word-wrapped to the pane width here, so the buffer keeps the
+// original lines for export.
+func (m *model) refreshLog() {
+	follow := m.viewport.AtBottom()
+	buf := m.logLines()
+	wrap := lipgloss.NewStyle().Width(m.viewport.Width)
+	lines := make([]string, len(buf))
+	for i, e := range buf {
+		lines[i] = wrap.Render(e.render(m.translateColors, m.highlights))
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+// mergingLogs reports whether the log pane interleaves followed container
+// logs: the active server's is followed, and so is at least one other.
+func (m *model) mergingLogs() bool {
+	return m.logStreams[m.activeName] != nil && len(m.logStreams) > 1
+}
+
+// logLines is what the log pane shows: the active server's buffer, with the
+// other followed servers' container log lines merged in by arrival order
+// while mergingLogs holds. Those lines still belong to their own servers, so
+// exports, clearing and the log file only ever see a server's own lines.
+func (m *model) logLines() []logEntry {
+	own := m.logs[m.activeName]
+	if !m.mergingLogs() {
+		return own
+	}
+	merged := append([]logEntry(nil), own...)
+	for name := range m.logStreams {
+		if name == m.activeName {
+			continue
+		}
+		for _, e := range m.logs[name] {
+			if e.tail {
+				merged = append(merged, e)
+			}
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].seq < merged[j].seq })
+	return merged
+}
+
+// tabBar renders one tab per server above the log pane.
+func (m *model) tabBar(width int) string {
+	tabs := make([]string, 0, len(m.servers))
+	for _, s := range m.servers {
+		if s.Name == m.activeName {
+			tabs = append(tabs, activeTabStyle.Render(s.Name))
+		} else {
+			tabs = append(tabs, tabStyle.Render(s.Name))
+		}
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+}
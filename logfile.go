@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	defaultLogFileBackups = 3
+	logFileFlushInterval  = time.Second
+)
+
+// rolling log file
+
+// logFile appends every log line, from all servers, to a file on disk.
+// Writes are buffered and flushed at most once per logFileFlushInterval;
+// the status tick flushes whatever a quiet spell left behind, so a crash
+// loses at most about a second of output. Once the file would grow
+// past maxSize it rolls to path.1, path.1 to path.2 and so on, keeping
+// backups old files.
+type logFile struct {
+	path      string
+	maxSize   int64 // 0 disables rotation
+	backups   int
+	f         *os.File
+	w         *bufio.Writer
+	size      int64
+	lastFlush time.Time
+}
+
+func openLogFile(path string, maxSize int64, backups int) (*logFile, error) {
+	if backups <= 0 {
+		backups = defaultLogFileBackups
+	}
+	l := &logFile{path: path, maxSize: maxSize, backups: backups}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *logFile) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	l.f = f
+	l.w = bufio.NewWriter(f)
+	l.size = info.Size()
+	return nil
+}
+
+// write appends one line, prefixed with the date and time.
+func (l *logFile) write(text string) error {
+	line := fmt.Sprintf("%s %s\n", time.Now().Format("2006-01-02 15:04:05"), text)
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.w.WriteString(line)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return l.flush()
+}
+
+// flush writes out buffered lines if any are waiting and
+// logFileFlushInterval has passed since the last flush.
+func (l *logFile) flush() error {
+	if l.w.Buffered() == 0 || time.Since(l.lastFlush) < logFileFlushInterval {
+		return nil
+	}
+	l.lastFlush = time.Now()
+	return l.w.Flush()
+}
+
+func (l *logFile) rotate() error {
+	if err := l.close(); err != nil {
+		return err
+	}
+	for i := l.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return l.open()
+}
+
+// close flushes anything still buffered and closes the file.
+func (l *logFile) close() error {
+	if err := l.w.Flush(); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
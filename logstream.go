@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// docker log streaming
+
+// logStream follows `docker logs` for one server's container until
+// cancelled. Several can run at once, one per server.
+type logStream struct {
+	serverName string
+	lines      chan string
+	cancel     context.CancelFunc
+	err        error // set before lines is closed
+}
+
+type dockerLogLineMsg struct {
+	stream *logStream
+	line   string
+}
+
+type dockerLogEndMsg struct {
+	stream *logStream
+	err    error
+}
+
+// startLogStream launches `docker logs --follow` for s and returns the stream
+// along with the command that delivers its first line.
+func startLogStream(s serverConfig) (*logStream, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	st := &logStream{
+		serverName: s.Name,
+		lines:      make(chan string),
+		cancel:     cancel,
+	}
+
+	// fail ends the stream immediately; the error surfaces as a dockerLogEndMsg.
+	fail := func(err error) (*logStream, tea.Cmd) {
+		cancel()
+		st.err = err
+		close(st.lines)
+		return st, waitForLogLine(st)
+	}
+
+	args, err := dockerArgs(s, "logs")
+	if err != nil {
+		return fail(err)
+	}
+
+	pr, pw := io.Pipe()
+	cmd := dockerCommand(ctx, s, args...)
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		return fail(err)
+	}
+
+	go func() {
+		pw.CloseWithError(cmd.Wait())
+	}()
+
+	go func() {
+		defer close(st.lines)
+		defer pr.Close()
+		sc := bufio.NewScanner(pr)
+		for sc.Scan() {
+			select {
+			case st.lines <- sc.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		st.err = sc.Err()
+	}()
+
+	return st, waitForLogLine(st)
+}
+
+func waitForLogLine(st *logStream) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-st.lines
+		if !ok {
+			return dockerLogEndMsg{stream: st, err: st.err}
+		}
+		return dockerLogLineMsg{stream: st, line: line}
+	}
+}
+
+func (st *logStream) stop() {
+	st.cancel()
+}
@@ -1,15 +1,29 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gorcon/rcon"
 	"gopkg.in/yaml.v3"
@@ -18,41 +32,474 @@ import (
 // config types
 
 type serverConfig struct {
-	Name      string `yaml:"name"`
-	Address   string `yaml:"address"`
-	Password  string `yaml:"password"`
-	Container string `yaml:"container,omitempty"` // Docker container name or ID
+	Name               string          `yaml:"name" toml:"name"`
+	Address            addressList     `yaml:"address" toml:"address"` // host:port, or a list tried in order for failover
+	Password           string          `yaml:"password" toml:"password"`
+	AllowEmptyPassword bool            `yaml:"allow_empty_password,omitempty" toml:"allow_empty_password,omitempty"` // accept an empty password for servers that don't set one
+	Container          string          `yaml:"container,omitempty" toml:"container,omitempty"`                       // Docker container name or ID
+	ComposeFile        string          `yaml:"compose_file,omitempty" toml:"compose_file,omitempty"`                 // docker compose file; used with compose_service instead of container
+	ComposeService     string          `yaml:"compose_service,omitempty" toml:"compose_service,omitempty"`
+	DockerHost         string          `yaml:"docker_host,omitempty" toml:"docker_host,omitempty"`         // e.g. ssh://user@host; empty uses the local daemon
+	Timeout            time.Duration   `yaml:"timeout,omitempty" toml:"timeout,omitempty"`                 // RCON connect timeout, defaults to 5s
+	CommandTimeout     time.Duration   `yaml:"command_timeout,omitempty" toml:"command_timeout,omitempty"` // how long to wait for a response once connected, defaults to 10s
+	Schedule           []scheduleEntry `yaml:"schedule,omitempty" toml:"schedule,omitempty"`
+	Aliases            aliasMap        `yaml:"aliases,omitempty" toml:"aliases,omitempty"`                   // "!name" shortcuts, one command or a list; override global aliases
+	QueryAddress       string          `yaml:"query_address,omitempty" toml:"query_address,omitempty"`       // host:port for player counts: Server List Ping, or A2S_INFO for type source
+	Retries            int             `yaml:"retries,omitempty" toml:"retries,omitempty"`                   // extra dial attempts on connection errors
+	RetryDelay         time.Duration   `yaml:"retry_delay,omitempty" toml:"retry_delay,omitempty"`           // first backoff delay, doubled each retry; defaults to 1s
+	Group              string          `yaml:"group,omitempty" toml:"group,omitempty"`                       // section header in the server list
+	Tags               []string        `yaml:"tags,omitempty" toml:"tags,omitempty"`                         // labels for @tag: selectors in broadcasts and -exec
+	Type               string          `yaml:"type,omitempty" toml:"type,omitempty"`                         // minecraft, source, factorio, rust or generic (the default)
+	Protocol           string          `yaml:"protocol,omitempty" toml:"protocol,omitempty"`                 // tcp or websocket; defaults by type (websocket for rust)
+	CommandsFile       string          `yaml:"commands_file,omitempty" toml:"commands_file,omitempty"`       // Tab-completion list, relative to the config file
+	StopCommand        commandList     `yaml:"stop_command,omitempty" toml:"stop_command,omitempty"`         // sent over RCON before the docker stop, e.g. [save-all, stop]
+	StopGrace          time.Duration   `yaml:"stop_grace,omitempty" toml:"stop_grace,omitempty"`             // wait after stop_command before the docker stop, defaults to 10s
+	BlockedCommands    []string        `yaml:"blocked_commands,omitempty" toml:"blocked_commands,omitempty"` // prefixes or /regex/ that need confirming before they're sent
+	Block              bool            `yaml:"block,omitempty" toml:"block,omitempty"`                       // reject blocked_commands outright instead of asking
+	Proxy              string          `yaml:"proxy,omitempty" toml:"proxy,omitempty"`                       // socks5://[user:pass@]host:port to dial RCON through
+	RateLimit          float64         `yaml:"rate_limit,omitempty" toml:"rate_limit,omitempty"`             // most commands per second; extra ones queue up
+	Keepalive          time.Duration   `yaml:"keepalive,omitempty" toml:"keepalive,omitempty"`               // send keepalive_command on the idle pooled connection this often; off by default
+	KeepaliveCommand   string          `yaml:"keepalive_command,omitempty" toml:"keepalive_command,omitempty"`
+	Prompt             string          `yaml:"prompt,omitempty" toml:"prompt,omitempty"`                   // input prompt while this server is active, defaults to "> "
+	Placeholder        string          `yaml:"placeholder,omitempty" toml:"placeholder,omitempty"`         // hint shown in the empty input, e.g. "try /list"
+	QuickCommands      []string        `yaml:"quick_commands,omitempty" toml:"quick_commands,omitempty"`   // offered by the quick-command palette
+	PostAuthDelay      time.Duration   `yaml:"post_auth_delay,omitempty" toml:"post_auth_delay,omitempty"` // wait after logging in before the first command; off by default
+	PromptPassword     bool            `yaml:"prompt_password,omitempty" toml:"prompt_password,omitempty"` // leave password empty and ask for it on the first command
+	Templates          templateMap     `yaml:"templates,omitempty" toml:"templates,omitempty"`             // commands with {name} placeholders, filled in a form; override global templates
+
+	commands []string      // loaded from CommandsFile
+	blocked  []commandRule // compiled BlockedCommands
+	runtime  string        // the global container_runtime
+
+	tracePackets bool // -debug: log every RCON packet
+}
+
+const (
+	defaultDialTimeout    = 5 * time.Second
+	defaultCommandTimeout = 10 * time.Second
+	defaultRetryDelay     = time.Second
+)
+
+func (s serverConfig) dialTimeout() time.Duration {
+	if s.Timeout > 0 {
+		return s.Timeout
+	}
+	return defaultDialTimeout
+}
+
+func (s serverConfig) commandTimeout() time.Duration {
+	if s.CommandTimeout > 0 {
+		return s.CommandTimeout
+	}
+	return defaultCommandTimeout
 }
 
 type appConfig struct {
-	Servers []serverConfig `yaml:"servers"`
+	Servers          []serverConfig    `yaml:"servers" toml:"servers"`
+	IdleTimeout      time.Duration     `yaml:"idle_timeout,omitempty" toml:"idle_timeout,omitempty"` // close pooled RCON connections after this long unused
+	ShowTimestamps   bool              `yaml:"show_timestamps,omitempty" toml:"show_timestamps,omitempty"`
+	LogBufferLines   int               `yaml:"log_buffer_lines,omitempty" toml:"log_buffer_lines,omitempty"`     // lines kept per server log, defaults to 500
+	MaxResponseLines int               `yaml:"max_response_lines,omitempty" toml:"max_response_lines,omitempty"` // cut longer responses down, expandable on demand; 0 never cuts
+	LogFile          string            `yaml:"log_file,omitempty" toml:"log_file,omitempty"`                     // append every log line to this file
+	LogFileMaxMB     int               `yaml:"log_file_max_mb,omitempty" toml:"log_file_max_mb,omitempty"`       // roll log_file to .1, .2, ... past this size; 0 never rolls
+	LogFileBackups   int               `yaml:"log_file_backups,omitempty" toml:"log_file_backups,omitempty"`     // rolled files to keep, defaults to 3
+	PollInterval     time.Duration     `yaml:"poll_interval,omitempty" toml:"poll_interval,omitempty"`           // how often to check server reachability, defaults to 10s
+	Aliases          aliasMap          `yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	Templates        templateMap       `yaml:"templates,omitempty" toml:"templates,omitempty"`                 // e.g. kick: "kick {player} {reason}", for every server
+	SendKey          string            `yaml:"send_key,omitempty" toml:"send_key,omitempty"`                   // e.g. "alt+enter" to make Enter insert newlines
+	Keybindings      map[string]string `yaml:"keybindings,omitempty" toml:"keybindings,omitempty"`             // action name -> key(s), see keyActions
+	DiscordWebhook   string            `yaml:"discord_webhook,omitempty" toml:"discord_webhook,omitempty"`     // post errors and exited containers here
+	TranslateColors  bool              `yaml:"translate_colors,omitempty" toml:"translate_colors,omitempty"`   // render Minecraft § codes as colors instead of stripping them
+	ExportFormat     string            `yaml:"export_format,omitempty" toml:"export_format,omitempty"`         // "plain" (default) or "raw" to keep § codes in exports
+	LogLevel         string            `yaml:"log_level,omitempty" toml:"log_level,omitempty"`                 // "quiet", "normal" (default) or "verbose"
+	Theme            themeConfig       `yaml:"theme,omitempty" toml:"theme,omitempty"`                         // "dark" (default), "light", or role colors on top of a base theme
+	Highlights       []highlightConfig `yaml:"highlights,omitempty" toml:"highlights,omitempty"`               // log text to pick out in color
+	Watch            []watchConfig     `yaml:"watch,omitempty" toml:"watch,omitempty"`                         // log lines that ring the bell or stick in the status bar
+	Profiles         []string          `yaml:"profiles,omitempty" toml:"profiles,omitempty"`                   // names listed by -list-profiles, each loaded from ~/.config/bubblecon/<name>.yaml
+	ContainerRuntime string            `yaml:"container_runtime,omitempty" toml:"container_runtime,omitempty"` // container CLI, "docker" (default) or a compatible one like "podman"
+	MaxConcurrency   int               `yaml:"max_concurrency,omitempty" toml:"max_concurrency,omitempty"`     // servers a broadcast sends to at once, defaults to 10
+
+	highlights []highlight // compiled Highlights
+	watches    []watchRule // compiled Watch
+}
+
+// loadConfigs loads every file in order. Top-level settings from later files
+// override earlier ones, and servers are concatenated, with a later server
+// of the same name replacing the earlier definition. Duplicates are returned
+// as warnings.
+func loadConfigs(paths []string) (appConfig, []string, error) {
+	var (
+		cfg      appConfig
+		servers  []serverConfig
+		warnings []string
+	)
+	index := make(map[string]int)
+	origin := make(map[string]string)
+
+	for _, path := range paths {
+		fileServers, fileWarnings, err := loadConfig(path, &cfg)
+		if err != nil {
+			return cfg, warnings, err
+		}
+		warnings = append(warnings, fileWarnings...)
+		for _, s := range fileServers {
+			if i, dup := index[s.Name]; dup {
+				warnings = append(warnings, fmt.Sprintf("server %q from %s overrides the definition in %s", s.Name, path, origin[s.Name]))
+				servers[i] = s
+			} else {
+				index[s.Name] = len(servers)
+				servers = append(servers, s)
+			}
+			origin[s.Name] = path
+		}
+	}
+
+	cfg.Servers = servers
+	if len(cfg.Servers) == 0 {
+		return cfg, warnings, fmt.Errorf("no servers defined in %s", strings.Join(paths, ", "))
+	}
+	switch cfg.ExportFormat {
+	case "", "plain", "raw":
+	default:
+		return cfg, warnings, fmt.Errorf("export_format must be \"plain\" or \"raw\", got %q", cfg.ExportFormat)
+	}
+	if cfg.MaxConcurrency < 0 {
+		return cfg, warnings, fmt.Errorf("max_concurrency must be positive, got %d", cfg.MaxConcurrency)
+	}
+	if _, ok := logLevels[cfg.LogLevel]; !ok {
+		return cfg, warnings, fmt.Errorf("log_level must be \"quiet\", \"normal\" or \"verbose\", got %q", cfg.LogLevel)
+	}
+	for i := range cfg.Servers {
+		cfg.Servers[i].runtime = cfg.ContainerRuntime
+	}
+	var err error
+	if cfg.highlights, err = compileHighlights(cfg.Highlights); err != nil {
+		return cfg, warnings, err
+	}
+	if cfg.watches, err = compileWatches(cfg.Watch); err != nil {
+		return cfg, warnings, err
+	}
+	return cfg, warnings, nil
 }
 
-func loadConfig(path string) ([]serverConfig, error) {
+// loadConfig decodes path on top of cfg, so only the settings present in the
+// file are overwritten, and returns the servers the file defines along with warnings about them.
+func loadConfig(path string, cfg *appConfig) ([]serverConfig, []string, error) {
 	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Only the config file itself counts as missing; files it refers
+		// to fail with their own errors further down.
+		return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, &missingConfigError{path: path, err: err})
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	cfg.Servers = nil
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		if err := toml.Unmarshal(data, cfg); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse TOML in %s: %w", path, err)
+		}
+	default:
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
+		}
+	}
+	servers := cfg.Servers
+	cfg.Servers = nil
+
+	var warnings []string
+	for i := range servers {
+		s := &servers[i]
+		pw, err := resolvePassword(s.Password, filepath.Dir(path))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: server %q: %w", path, s.Name, err)
+		}
+		s.Password = pw
+		if s.Type, err = normalizeType(s.Type); err != nil {
+			return nil, nil, fmt.Errorf("%s: server %q: %w", path, s.Name, err)
+		}
+		warnings = append(warnings, s.fillDefaultPorts()...)
+		if s.Protocol, err = s.normalizeProtocol(); err != nil {
+			return nil, nil, fmt.Errorf("%s: server %q: %w", path, s.Name, err)
+		}
+		if s.blocked, err = compileCommandRules(s.BlockedCommands); err != nil {
+			return nil, nil, fmt.Errorf("%s: server %q: %w", path, s.Name, err)
+		}
+		if s.CommandsFile != "" {
+			p := s.CommandsFile
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(filepath.Dir(path), p)
+			}
+			if s.commands, err = loadCommands(p); err != nil {
+				return nil, nil, fmt.Errorf("%s: server %q: failed to read commands file: %w", path, s.Name, err)
+			}
+		}
 	}
 
-	var cfg appConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := validateServers(servers); err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid config:\n%w", path, err)
 	}
+	return servers, warnings, nil
+}
 
-	if len(cfg.Servers) == 0 {
-		return nil, fmt.Errorf("no servers defined in %s", path)
+// validateServers checks that every server can be dialed as configured,
+// returning one line per problem found.
+func validateServers(servers []serverConfig) error {
+	var problems []error
+	for i, s := range servers {
+		label := fmt.Sprintf("server %q", s.Name)
+		if strings.TrimSpace(s.Name) == "" {
+			label = fmt.Sprintf("server #%d", i+1)
+			problems = append(problems, fmt.Errorf("  %s: name: missing", label))
+		}
+		if len(s.Address) == 0 {
+			problems = append(problems, fmt.Errorf("  %s: address: missing", label))
+		}
+		for _, addr := range s.Address {
+			if addr == "" {
+				problems = append(problems, fmt.Errorf("  %s: address: missing", label))
+			} else if err := checkHostPort(addr); err != nil {
+				if missingPort(addr) {
+					err = fmt.Errorf("%w (add :port, or set a type that has a default)", err)
+				}
+				problems = append(problems, fmt.Errorf("  %s: address %q: %w", label, addr, err))
+			}
+		}
+		if s.QueryAddress != "" {
+			if err := checkHostPort(s.QueryAddress); err != nil {
+				problems = append(problems, fmt.Errorf("  %s: query_address %q: %w", label, s.QueryAddress, err))
+			}
+		}
+		if s.Proxy != "" {
+			if _, err := parseProxy(s.Proxy); err != nil {
+				problems = append(problems, fmt.Errorf("  %s: proxy: %w", label, err))
+			}
+		}
+		if s.Keepalive > 0 && s.keepaliveCommand() == "" {
+			problems = append(problems, fmt.Errorf("  %s: keepalive_command: missing (type %s has no default)", label, s.Type))
+		}
+		if s.PostAuthDelay < 0 {
+			problems = append(problems, fmt.Errorf("  %s: post_auth_delay: must be positive, got %v", label, s.PostAuthDelay))
+		}
+		if s.RateLimit < 0 {
+			problems = append(problems, fmt.Errorf("  %s: rate_limit: must be positive, got %v", label, s.RateLimit))
+		}
+		if s.Password == "" && !s.AllowEmptyPassword && !s.PromptPassword {
+			problems = append(problems, fmt.Errorf("  %s: password: missing (set allow_empty_password if the server has none, or prompt_password to be asked for it)", label))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// missingPort reports whether addr is a bare host that just lacks its port.
+func missingPort(addr string) bool {
+	_, ok := bareHost(addr)
+	return ok
+}
+
+// bareHost returns the host of an address without a port: a hostname, an
+// IPv4 address, or an IPv6 address with or without its brackets.
+func bareHost(addr string) (string, bool) {
+	if ip := net.ParseIP(addr); ip != nil {
+		return addr, true
+	}
+	_, _, err := net.SplitHostPort(addr)
+	var addrErr *net.AddrError
+	if !errors.As(err, &addrErr) || addrErr.Err != "missing port in address" {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"), true
+}
+
+// fillDefaultPorts gives addresses without a port the default RCON port of
+// the server's type, returning a warning for each. Addresses it can't
+// complete are left for validateServers to report.
+func (s *serverConfig) fillDefaultPorts() []string {
+	port := s.profile().rconPort
+	if port == "" {
+		return nil
+	}
+	var warnings []string
+	for i, addr := range s.Address {
+		host, ok := bareHost(addr)
+		if !ok {
+			continue
+		}
+		s.Address[i] = net.JoinHostPort(host, port)
+		warnings = append(warnings, fmt.Sprintf("server %q: address has no port, using the %s default: %s", s.Name, s.Type, s.Address[i]))
+	}
+	return warnings
+}
+
+// checkHostPort reports whether addr is a host:port with a usable port.
+func checkHostPort(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		if fixed, ok := unbracketedIPv6(addr); ok {
+			return fmt.Errorf("IPv6 addresses need brackets around the host, e.g. %s", fixed)
+		}
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) {
+			return errors.New(addrErr.Err) // without the address, which the caller quotes
+		}
+		return err
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("port %q is not a number between 1 and 65535", port)
+	}
+	return nil
+}
+
+// unbracketedIPv6 reports whether addr is an IPv6 address written without
+// the brackets that separate it from a port, returning it as it should be.
+func unbracketedIPv6(addr string) (string, bool) {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return net.JoinHostPort(addr, "port"), true
+	}
+	// "2001:db8::1:25575" only parses once the port is split off; ports
+	// that are also valid hex groups ("::1:8080") are caught above.
+	if i := strings.LastIndex(addr, ":"); i > 0 {
+		if ip := net.ParseIP(addr[:i]); ip != nil && ip.To4() == nil {
+			return net.JoinHostPort(addr[:i], addr[i+1:]), true
+		}
+	}
+	return "", false
+}
+
+// configPaths collects repeated -config flags.
+type configPaths []string
+
+func (c *configPaths) String() string { return strings.Join(*c, ",") }
+
+func (c *configPaths) Set(path string) error {
+	*c = append(*c, path)
+	return nil
+}
+
+// defaultConfig picks the config to load when no -config or -profile is
+// given: $BUBBLECON_CONFIG, else config.yaml in configDir if it exists, else
+// config.yaml in the working directory. It also says where the choice
+// came from, for the startup log.
+func defaultConfig() (path, source string) {
+	if p := os.Getenv("BUBBLECON_CONFIG"); p != "" {
+		return p, "$BUBBLECON_CONFIG"
+	}
+	if dir, err := configDir(); err == nil {
+		p := filepath.Join(dir, "config.yaml")
+		if _, err := os.Stat(p); err == nil {
+			return p, "the user config directory"
+		}
+	}
+	return "config.yaml", "the working directory"
+}
+
+var envRefPattern = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// resolvePassword expands a secret reference: ${ENV_VAR}, file:path (e.g. a
+// Docker secret; relative paths are relative to the config file in dir) or
+// exec:command (e.g. "exec:pass show rcon/survival", run without a shell).
+// Trailing newlines are trimmed from files and command output. Anything else
+// is a literal.
+func resolvePassword(raw, dir string) (string, error) {
+	if m := envRefPattern.FindStringSubmatch(raw); m != nil {
+		val, ok := os.LookupEnv(m[1])
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", m[1])
+		}
+		return val, nil
+	}
+
+	switch {
+	case strings.HasPrefix(raw, "file:"):
+		p := strings.TrimPrefix(raw, "file:")
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "", fmt.Errorf("password %q: %w", raw, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(raw, "exec:"):
+		args := strings.Fields(strings.TrimPrefix(raw, "exec:"))
+		if len(args) == 0 {
+			return "", fmt.Errorf("password %q: no command given", raw)
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("password %q: %w", raw, err)
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+	return raw, nil
+}
+
+// layout
+
+const (
+	listWidth    = 24 // columns taken by the server list
+	minLogWidth  = 40
+	minLogHeight = 1
+	inputHeight  = 3
+	panelHeight  = 9               // rows outside the list and log panels' contents: their borders, the status bar and the input panel
+	chromeHeight = panelHeight + 1 // the same, plus the log's tab bar
+
+	minWidth  = listWidth + 4 + minLogWidth
+	minHeight = chromeHeight + minLogHeight + 2
+
+	inlineHeight = 20 // rows used with -inline, leaving the rest of the terminal to scrollback
+)
+
+// atLeast clamps v to a lower bound of lo.
+func atLeast(v, lo int) int {
+	if v < lo {
+		return lo
+	}
+	return v
+}
+
+// layout sizes the panes for the current window. The server list gives up
+// rows to the player panel when the active server has one.
+func (m *model) layout() {
+	rightWidth := m.logWidth()
+	listHeight := m.height - panelHeight
+	if p := m.playerPanel(); p != "" {
+		listHeight -= lipgloss.Height(p)
+	}
+	m.list.SetSize(listWidth, atLeast(listHeight, minLogHeight+2))
+	m.input.SetWidth(atLeast(m.width-2, minWidth-2))
+	m.viewport.Width = rightWidth
+	logHeight := m.height - chromeHeight
+	if m.resourceGraph(rightWidth) != "" {
+		logHeight--
 	}
+	m.viewport.Height = atLeast(logHeight, minLogHeight)
+}
+
+// logWidth is the width inside the log panel's border.
+func (m *model) logWidth() int {
+	return atLeast(m.width-listWidth-4, minLogWidth)
+}
 
-	return cfg.Servers, nil
+// tooSmall reports whether the window is known and can't fit the layout.
+func (m model) tooSmall() bool {
+	return m.width > 0 && (m.width < minWidth || m.height < minHeight)
 }
 
-// list item
+// focus
 
-type serverItem serverConfig
+type focusArea int
 
-func (s serverItem) Title() string       { return s.Name }
-func (s serverItem) Description() string { return s.Address }
-func (s serverItem) FilterValue() string { return s.Name }
+const (
+	focusInput focusArea = iota
+	focusList
+	focusLog
+	focusCount
+)
 
 // messages
 
@@ -61,6 +508,25 @@ type rconResultMsg struct {
 	cmd        string
 	output     string
 	err        error
+	rtt        time.Duration // time spent in Execute
+	attempt    int           // 1-based dial attempt that produced this result
+	retryable  bool          // the dial failed for a reason worth retrying
+	batch      *scriptBatch  // the script this command belongs to, if any
+	dialed     time.Duration // time spent dialing and authenticating, zero if a pooled connection was reused
+	endpoint   string        // the address it dialed, if it did
+	packets    []string      // -debug packet trace, including the handshake if it dialed
+	broadcast  int           // the broadcast this command is part of, 0 if none
+}
+
+// poolChangedMsg means a server may have gained or lost its pooled connection.
+type poolChangedMsg struct{}
+
+// reconnectMsg reports a manual reconnect to serverName.
+type reconnectMsg struct {
+	serverName string
+	endpoint   string
+	err        error
+	took       time.Duration
 }
 
 type dockerResultMsg struct {
@@ -73,52 +539,173 @@ type dockerResultMsg struct {
 // model
 
 type model struct {
-	list        list.Model
-	input       textarea.Model
-	logLines    []string
-	activeName  string
-	width       int
-	height      int
-	quitting    bool
-	statusLine  string
-	statusTimer time.Time
-	servers     []serverConfig
-}
-
-func initialModel(servers []serverConfig) model {
-	items := []list.Item{}
-	for _, s := range servers {
-		items = append(items, serverItem(s))
-	}
-
-	delegate := list.NewDefaultDelegate()
-	l := list.New(items, delegate, 24, 10)
+	list            list.Model
+	input           textarea.Model
+	viewport        viewport.Model
+	focus           focusArea
+	logs            map[string][]logEntry // keyed by server name
+	activeName      string
+	width           int
+	height          int
+	quitting        bool
+	statusLine      string
+	statusTimer     time.Time
+	servers         []serverConfig
+	pool            *connPool
+	timestamps      bool
+	logLimit        int                       // lines kept per server log
+	maxRespLines    int                       // responses longer than this are cut, 0 to keep them whole
+	logFile         *logFile                  // nil unless log_file is set
+	webhook         string                    // Discord webhook URL, "" to disable notifications
+	containers      map[string]containerState // last known container state, keyed by server name
+	translateColors bool                      // render § codes in responses as colors
+	highlights      []highlight               // compiled highlights config
+	watches         []watchRule               // compiled watch config
+	watchAlert      string                    // last status-bar watch match, shown until the next key press
+	exportRaw       bool                      // keep § codes in exported logs
+	logLevel        logLevel
+	histories       map[string]*cmdHistory // keyed by server name
+	pollEvery       time.Duration
+	logStreams      map[string]*logStream // followed container logs, keyed by server name
+	logSeq          uint64                // last logEntry.seq handed out
+	aliases         aliasMap
+	templates       templateMap // global command templates
+	keys            keyMap
+	showHelp        bool                      // the keybinding overlay is open
+	palette         *palette                  // quick-command palette, nil unless open
+	tmplMenu        *templateMenu             // template picker and form, nil unless open
+	info            *serverInfo               // connection-details overlay, nil unless open
+	password        *passwordPrompt           // masked password entry, nil unless open
+	passwords       map[string]string         // entered at the password prompt, kept for the session only
+	lastOK          map[string]time.Time      // when each server last answered a command
+	broadcasts      map[int]*broadcastTally   // broadcasts still waiting on results, by id
+	broadcastSeq    int                       // id of the last broadcast started
+	sendSlots       chan struct{}             // one token per command running, up to max_concurrency
+	completion      *completion               // Tab completion in progress, nil otherwise
+	prompt          *confirmPrompt            // open yes/no question, nil otherwise
+	batch           *scriptBatch              // running script, nil otherwise
+	initScript      *runScriptMsg             // -script to start once the program runs
+	recorder        *recorder                 // -record file, nil unless recording
+	replay          []recordEvent             // -replay events, nil unless replaying
+	replayInstant   bool                      // replay without the recorded pauses
+	inline          bool                      // drawn below the shell prompt instead of on the alternate screen
+	spinner         spinner.Model             // shown in the status bar while requests are in flight
+	inFlight        int                       // RCON commands and docker actions sent and not yet answered
+	dryRun          bool                      // log what Enter would send instead of sending it
+	placeholder     string                    // input hint for servers without their own
+	cfgPaths        configPaths               // config files, read again on reload
+	tracePackets    bool                      // -debug, applied to reloaded servers too
+	themeFlag       string                    // -theme, which wins over a reloaded theme
+	noDocker        bool                      // containers are configured but the container runtime isn't installed
+	nextSend        map[string]time.Time      // next free rate_limit slot, keyed by server name
+	sendCtx         context.Context           // commands sent from the UI; cancelled by the cancel key
+	cancelSends     context.CancelFunc        // cancels sendCtx
+	cancelled       bool                      // the cancel key was used and nothing has been sent since
+	stats           map[string]containerStats // last docker stats, keyed by server name
+	statHistory     map[string]*statsSeries   // sampled for the resource graph, keyed by server name
+	reach           map[string]reachState
+	players         map[string]*playerCount
+	collapsed       map[string]bool     // server list groups, keyed by group name
+	playerLists     map[string][]string // last parsed player list, keyed by server name
+	delegate        list.ItemDelegate   // the list's delegate, for mouse hit-testing
+}
+
+func initialModel(cfg appConfig, pool *connPool) model {
+	servers := cfg.Servers
+
+	delegate := serverDelegate{list.NewDefaultDelegate()}
+	l := list.New(nil, delegate, listWidth, 10)
 	l.Title = "Servers"
+	l.SetShowTitle(false) // the panel border carries the title
 	l.SetShowHelp(false)
 	l.DisableQuitKeybindings()
 	l.SetFilteringEnabled(false)
 
+	keys, keyWarnings := newKeyMap(cfg.Keybindings, cfg.SendKey)
+
 	ta := textarea.New()
-	ta.Placeholder = "Type RCON command, press Enter to send"
-	ta.Prompt = "> "
+	ta.Placeholder = inputPlaceholder(keys)
+	ta.Prompt = defaultPrompt
 	ta.Focus()
-	ta.SetHeight(3)
+	ta.SetHeight(inputHeight)
 	ta.ShowLineNumbers = false
 
+	sp := spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(lipgloss.NewStyle().Foreground(activeTheme.Accent)))
+
 	m := model{
-		list:       l,
-		input:      ta,
-		logLines:   []string{"Ready."},
-		activeName: "",
-		servers:    servers,
+		spinner:         sp,
+		list:            l,
+		delegate:        delegate,
+		input:           ta,
+		focus:           focusInput,
+		viewport:        viewport.New(40, 10),
+		activeName:      "",
+		logs:            make(map[string][]logEntry),
+		stats:           make(map[string]containerStats),
+		statHistory:     make(map[string]*statsSeries),
+		nextSend:        make(map[string]time.Time),
+		reach:           make(map[string]reachState),
+		players:         make(map[string]*playerCount),
+		collapsed:       make(map[string]bool),
+		playerLists:     make(map[string][]string),
+		servers:         servers,
+		pool:            pool,
+		timestamps:      cfg.ShowTimestamps,
+		logLimit:        cfg.LogBufferLines,
+		maxRespLines:    cfg.MaxResponseLines,
+		webhook:         cfg.DiscordWebhook,
+		containers:      make(map[string]containerState),
+		broadcasts:      make(map[int]*broadcastTally),
+		logStreams:      make(map[string]*logStream),
+		lastOK:          make(map[string]time.Time),
+		passwords:       make(map[string]string),
+		sendSlots:       make(chan struct{}, cmp.Or(cfg.MaxConcurrency, defaultMaxConcurrency)),
+		translateColors: cfg.TranslateColors,
+		highlights:      cfg.highlights,
+		watches:         cfg.watches,
+		exportRaw:       cfg.ExportFormat == "raw",
+		logLevel:        logLevels[cfg.LogLevel],
+		histories:       loadHistory(),
+		pollEvery:       cfg.PollInterval,
+		aliases:         cfg.Aliases,
+		templates:       cfg.Templates,
+		keys:            keys,
+		placeholder:     ta.Placeholder,
+	}
+	m.sendCtx, m.cancelSends = context.WithCancel(context.Background())
+	if m.pollEvery <= 0 {
+		m.pollEvery = defaultPollInterval
+	}
+	if m.logLimit <= 0 {
+		m.logLimit = defaultLogBufferLines
+	}
+	var logFileErr error
+	if cfg.LogFile != "" {
+		m.logFile, logFileErr = openLogFile(cfg.LogFile, int64(cfg.LogFileMaxMB)<<20, cfg.LogFileBackups)
 	}
-
 	if len(servers) > 0 {
 		m.activeName = servers[0].Name
-		m.list.Select(0)
-		m.pushLog(fmt.Sprintf("Active server: %s", m.activeName))
+		// Start where the last session left off, if that server is still configured.
+		if last := loadActiveServer(); m.serverByName(last) != nil {
+			m.activeName = last
+		}
+		m.applyPrompt()
+		m.rebuildList()
+		m.pushLog(logInfo, "bubblecon "+versionString()+". Ready.")
+		m.pushLog(logInfo, fmt.Sprintf("Active server: %s", m.activeName))
 	} else {
-		m.pushLog("⚠️ No servers configured. Please check config.yaml")
+		m.pushLog(logWarn, "⚠️ No servers configured. Please check your config file")
+	}
+	for _, w := range keyWarnings {
+		m.pushLog(logWarn, "⚠️ "+w)
+	}
+	if runtime, missing := runtimeMissing(servers); missing {
+		m.noDocker = true
+		m.keys.disableGroup("Docker")
+		m.pushLog(logWarn, fmt.Sprintf("⚠️ %s isn't installed (not on PATH); container actions are disabled", runtime))
+	}
+	if logFileErr != nil {
+		m.pushLog(logWarn, fmt.Sprintf("⚠️ %v", logFileErr))
 	}
 
 	return m
@@ -130,220 +717,1171 @@ func (m *model) activeServer() *serverConfig {
 	if m.activeName == "" {
 		return nil
 	}
+	return m.serverByName(m.activeName)
+}
+
+func (m *model) serverByName(name string) *serverConfig {
 	for i := range m.servers {
-		if m.servers[i].Name == m.activeName {
+		if m.servers[i].Name == name {
 			return &m.servers[i]
 		}
 	}
 	return nil
 }
 
-func (m *model) pushLog(line string) {
-	const maxLines = 500
-	m.logLines = append(m.logLines, line)
-	if len(m.logLines) > maxLines {
-		m.logLines = m.logLines[len(m.logLines)-maxLines:]
+// activeHistory returns the command history of the active server.
+func (m *model) activeHistory() *cmdHistory {
+	h, ok := m.histories[m.activeName]
+	if !ok {
+		h = &cmdHistory{}
+		m.histories[m.activeName] = h
 	}
+	return h
 }
 
-func (m *model) setStatus(msg string) {
-	m.statusLine = msg
-	m.statusTimer = time.Now()
+// setActive makes name the active server and shows its log.
+func (m *model) setActive(name string) {
+	if name == m.activeName {
+		return
+	}
+	m.activeName = name
+	m.applyPrompt()
+	m.layout()
+	m.refreshLog()
+	m.viewport.GotoBottom()
+	m.pushLog(logInfo, fmt.Sprintf("Active server: %s", m.activeName))
 }
 
-// commands
+const defaultPrompt = "> "
 
-func sendRCONCmd(s serverConfig, cmd string) tea.Cmd {
-	return func() tea.Msg {
-		client, err := rcon.Dial(s.Address, s.Password)
-		if err != nil {
-			return rconResultMsg{
-				serverName: s.Name,
-				cmd:        cmd,
-				err:        fmt.Errorf("failed to connect: %w", err),
-			}
+// inputPlaceholder is the input's hint for how to send with keys.
+func inputPlaceholder(keys keyMap) string {
+	if !keys.matches(tea.KeyMsg{Type: tea.KeyEnter}, "send") {
+		return fmt.Sprintf("Type RCON commands, one per line, press %s to send", keys.label("send"))
+	}
+	return fmt.Sprintf("Type RCON command, press %s to send", keys.label("send"))
+}
+
+// applyPrompt shows the active server's prompt and placeholder in the
+// input, falling back to the defaults for servers that don't set them.
+func (m *model) applyPrompt() {
+	m.input.Prompt, m.input.Placeholder = defaultPrompt, m.placeholder
+	if s := m.activeServer(); s != nil {
+		if s.Prompt != "" {
+			m.input.Prompt = s.Prompt
 		}
-		defer client.Close()
+		if s.Placeholder != "" {
+			m.input.Placeholder = s.Placeholder
+		}
+	}
+}
 
-		resp, err := client.Execute(cmd)
-		return rconResultMsg{
-			serverName: s.Name,
-			cmd:        cmd,
-			output:     resp,
-			err:        err,
+// lineEditKey reports whether msg is one of the input's readline-style
+// editing keys: Ctrl+A and Ctrl+E for line start and end, Ctrl+W to delete
+// the previous word and Ctrl+U to delete back to the line start.
+func (m *model) lineEditKey(msg tea.KeyMsg) bool {
+	km := m.input.KeyMap
+	return key.Matches(msg, km.LineStart, km.LineEnd, km.DeleteWordBackward, km.DeleteBeforeCursor)
+}
+
+// updateFilter routes keys to the list while its filter prompt is open.
+// Enter jumps to the highlighted match; either way filtering is switched
+// off again once the prompt closes so the list keeps out of typing.
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	switch msg.String() {
+	case "enter":
+		if it, ok := m.list.SelectedItem().(serverItem); ok {
+			m.setActive(it.Name)
 		}
+		fallthrough
+	case "esc":
+		m.list.ResetFilter()
+		m.list.SetFilteringEnabled(false)
+		m.rebuildList()
 	}
+	return m, cmd
 }
 
-func dockerAction(s serverConfig, action string) tea.Cmd {
-	return func() tea.Msg {
-		if s.Container == "" {
-			return dockerResultMsg{
-				serverName: s.Name,
-				action:     action,
-				err:        fmt.Errorf("no container configured"),
+// setFocus moves keyboard focus to another pane. Leaving the list puts its
+// selection back on the active server.
+func (m *model) setFocus(f focusArea) tea.Cmd {
+	leaving := m.focus == focusList && f != focusList
+	m.focus = f
+	if leaving {
+		m.rebuildList()
+	}
+	if f == focusInput {
+		return m.input.Focus()
+	}
+	m.input.Blur()
+	return nil
+}
+
+// updateInput handles keys while the command input has focus.
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		if m.input.LineCount() <= 1 {
+			if cmd, ok := m.activeHistory().prev(); ok {
+				m.input.SetValue(cmd)
 			}
+			return m, nil
 		}
+	case "down":
+		if m.input.LineCount() <= 1 {
+			m.input.SetValue(m.activeHistory().next())
+			return m, nil
+		}
+	}
+	if m.keys.matches(msg, "send") {
+		return m.submitInput()
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
 
-		var args []string
-		switch action {
-		case "start":
-			args = []string{"start", s.Container}
-		case "stop":
-			args = []string{"stop", s.Container}
-		case "restart":
-			args = []string{"restart", s.Container}
-		case "status":
-			args = []string{"inspect", "--format", "{{.State.Status}}", s.Container}
-		default:
-			return dockerResultMsg{
-				serverName: s.Name,
-				action:     action,
-				err:        fmt.Errorf("unknown action: %s", action),
+// updateList handles keys while the server list has focus: arrows move the
+// selection, Enter activates a server or toggles a group, Space toggles
+// the selected group and / starts the jump-to filter.
+func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "/":
+		m.list.SetFilteringEnabled(true)
+	case "enter", " ":
+		switch it := m.list.SelectedItem().(type) {
+		case groupHeader:
+			m.toggleGroup(it.name)
+		case serverItem:
+			if msg.String() == "enter" {
+				m.setActive(it.Name)
+			} else if it.Group != "" {
+				m.toggleGroup(it.Group)
 			}
 		}
+		return m, nil
+	case "esc":
+		return m, m.setFocus(focusInput)
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
 
-		cmd := exec.Command("docker", args...)
-		output, err := cmd.CombinedOutput()
-		
-		return dockerResultMsg{
-			serverName: s.Name,
-			action:     action,
-			output:     string(output),
-			err:        err,
+// updateMouse selects servers clicked in the list and scrolls the log pane
+// with the wheel. Events anywhere else are ignored.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.showHelp {
+		return m, nil
+	}
+	// Both panels' contents start one row and column in, past the border.
+	inMain := msg.Y >= 1 && msg.Y < m.height-panelHeight+1
+	inList := inMain && msg.X >= 1 && msg.X <= listWidth
+	inLog := inMain && msg.X > listWidth+2
+
+	switch {
+	case inLog && (msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown):
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	case inList && msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		idx, ok := m.listIndexAt(msg.Y - 1)
+		if !ok {
+			return m, nil
+		}
+		m.list.Select(idx)
+		switch it := m.list.SelectedItem().(type) {
+		case serverItem:
+			m.setActive(it.Name)
+		case groupHeader:
+			m.toggleGroup(it.name)
 		}
 	}
+	return m, nil
 }
 
-// tea.Model
+// dockerTarget returns the active server if it has a container configured
+// and this isn't a replay, logging why not otherwise.
+func (m *model) dockerTarget() *serverConfig {
+	s := m.activeServer()
+	if s == nil {
+		m.pushLog(logError, "❌ No active server selected.")
+		return nil
+	}
+	if m.replay != nil {
+		m.pushLog(logError, "❌ Docker actions don't run while replaying a recording.")
+		return nil
+	}
+	if !s.hasContainer() {
+		m.pushLog(logWarn, fmt.Sprintf("[%s] ⚠️ No container configured", s.Name))
+		return nil
+	}
+	return s
+}
 
-func (m model) Init() tea.Cmd { return textarea.Blink }
+// stopLogStream cancels the named server's docker log stream, if one is
+// running.
+func (m *model) stopLogStream(name string) {
+	st := m.logStreams[name]
+	if st == nil {
+		return
+	}
+	st.stop()
+	delete(m.logStreams, name)
+	m.pushLogFor(name, logDocker, fmt.Sprintf("[%s] 🐳 Stopped following logs", name))
+	m.refreshLog()
+}
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
+const statusTimeout = 5 * time.Second
 
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.list.SetSize(24, m.height-5)
-		m.input.SetWidth(m.width - 26)
+// statusTickMsg drives the check that clears stale status messages.
+type statusTickMsg struct{}
+
+func statusTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return statusTickMsg{} })
+}
+
+// setStatus shows msg in the status bar until statusTimeout passes, after
+// which the active server summary returns.
+func (m *model) setStatus(msg string) {
+	m.statusLine = msg
+	m.statusTimer = time.Now()
+}
+
+// pendingWarnAt is how many outstanding requests make the pending count
+// turn into a warning that commands are backing up.
+const pendingWarnAt = 3
+
+// sending counts n more requests in flight, returning the spinner's first
+// tick if it was idle.
+func (m *model) sending(n int) tea.Cmd {
+	m.cancelled = false
+	idle := m.inFlight == 0
+	m.inFlight += n
+	if idle && m.inFlight > 0 {
+		return m.spinner.Tick
+	}
+	return nil
+}
+
+// cancellable reports whether there are commands or a script to cancel.
+func (m *model) cancellable() bool {
+	return m.inFlight > 0 || m.batch != nil
+}
+
+// cancelPending abandons every command sent from the UI that hasn't been
+// answered yet, including any still waiting for a rate_limit slot, and the
+// rest of a running script. Each abandoned command lands as cancelled.
+func (m *model) cancelPending() {
+	m.cancelSends()
+	m.sendCtx, m.cancelSends = context.WithCancel(context.Background())
+	m.nextSend = make(map[string]time.Time)
+	m.cancelled = true
+	if b := m.batch; b != nil {
+		m.pushLogFor(b.serverName, logWarn, fmt.Sprintf("[%s] 📋 Script cancelled at %d/%d", b.serverName, b.next, len(b.cmds)))
+		m.batch = nil
+	}
+	m.pushLog(logWarn, "🚫 Cancelling pending commands")
+	m.setStatus("Cancelling...")
+}
+
+// landed counts one in-flight request as finished. The spinner stops on
+// its next tick once none are left.
+func (m *model) landed() {
+	if m.inFlight > 0 {
+		m.inFlight--
+	}
+}
+
+// submitInput sends what's in the input box and clears it.
+func (m model) submitInput() (tea.Model, tea.Cmd) {
+	raw := m.input.Value()
+	m.input.Reset()
+	return m.submit(raw)
+}
+
+// submit sends each non-empty line of raw to the active server as a
+// separate command, in order. A line starting with a selector, like
+// "@tag:survival say hi" or "@lobby-* say hi", is broadcast to every server
+// it matches instead.
+func (m model) submit(raw string) (tea.Model, tea.Cmd) {
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return m, nil
+	}
+	s := m.activeServer()
+	if s == nil {
+		m.pushLog(logError, "❌ No active server selected.")
+		return m, nil
+	}
+	if m.replay != nil {
+		m.pushLog(logError, "❌ Commands aren't sent while replaying a recording.")
+		return m, nil
+	}
+	if name := m.missingPassword(*s, lines); name != "" && !m.dryRun {
+		m.askPassword(name, raw)
 		return m, nil
+	}
 
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
-			m.quitting = true
-			return m, tea.Quit
-		case "tab":
-			total := len(m.list.Items())
-			if total > 0 {
-				idx := (m.list.Index() + 1) % total
-				m.list.Select(idx)
-				if it, ok := m.list.SelectedItem().(serverItem); ok {
-					m.activeName = it.Name
-					m.pushLog(fmt.Sprintf("Active server: %s", m.activeName))
-				}
+	var sends []outgoing
+	var guarded []string // commands that need confirming first
+	for _, line := range lines {
+		m.activeHistory().add(line)
+		targets, cmd, broadcast := []serverConfig{*s}, line, 0
+		if sel, rest, ok := broadcastLine(line); ok {
+			matched, err := selectServers(m.servers, sel)
+			if err == nil && rest == "" {
+				err = fmt.Errorf("nothing to send to %s", sel)
 			}
-			return m, nil
-		case "ctrl+s":
-			// Docker start
-			s := m.activeServer()
-			if s == nil {
-				m.pushLog("❌ No active server selected.")
-				return m, nil
+			if err != nil {
+				m.pushLog(logError, fmt.Sprintf("❌ %v", err))
+				continue
 			}
-			if s.Container == "" {
-				m.pushLog(fmt.Sprintf("[%s] ⚠️ No container configured", s.Name))
-				return m, nil
+			m.pushLog(logInfo, fmt.Sprintf("📢 Broadcasting to %s: %s", serverNames(matched), rest))
+			m.broadcastSeq++
+			targets, cmd, broadcast = matched, rest, m.broadcastSeq
+		}
+
+		for _, t := range targets {
+			if m.dryRun {
+				m.logDryRun(t, cmd)
+				continue
 			}
-			m.pushLog(fmt.Sprintf("[%s] 🐳 Starting container: %s", s.Name, s.Container))
-			m.setStatus("Starting container...")
-			return m, dockerAction(*s, "start")
-		case "ctrl+x":
-			// Docker stop
-			s := m.activeServer()
+			expanded, isAlias := expandAlias(cmd, t.Aliases, m.aliases)
+			for i, cmdStr := range expanded {
+				if isAlias {
+					m.pushLogFor(t.Name, logCommand, fmt.Sprintf("[%s] > %s", t.Name, aliasStep(cmd, expanded, i)))
+					m.record(recordEvent{Type: "command", Server: t.Name, Cmd: cmd, Expanded: cmdStr})
+				} else {
+					m.pushLogFor(t.Name, logCommand, fmt.Sprintf("[%s] > %s", t.Name, cmdStr))
+					m.record(recordEvent{Type: "command", Server: t.Name, Cmd: cmdStr})
+				}
+				if rule, ok := t.blockedBy(cmdStr); ok {
+					if t.Block {
+						m.pushLogFor(t.Name, logWarn, fmt.Sprintf("[%s] 🚫 Not sent: %q matches blocked command %q", t.Name, cmdStr, rule))
+						continue
+					}
+					if t.Name != s.Name {
+						guarded = append(guarded, fmt.Sprintf("%s on %s", cmdStr, t.Name))
+					} else {
+						guarded = append(guarded, cmdStr)
+					}
+				}
+				sends = append(sends, outgoing{server: t, cmd: cmdStr, broadcast: broadcast, origin: s.Name, line: cmd})
+			}
+		}
+	}
+	if len(sends) == 0 {
+		return m, nil
+	}
+	if len(guarded) > 0 {
+		name := s.Name
+		m.confirm(fmt.Sprintf("[%s] Really send %s?", name, strings.Join(guarded, ", ")), func(m *model) tea.Cmd {
+			m.setStatus("Sending...")
+			return m.sendAll(sends)
+		}, func(m *model) {
+			m.pushLogFor(name, logWarn, fmt.Sprintf("[%s] 🚫 Cancelled", name))
+		})
+		return m, nil
+	}
+	m.setStatus("Sending...")
+	return m, m.sendAll(sends)
+}
+
+// outgoing is one command submitInput is about to send.
+type outgoing struct {
+	server    serverConfig
+	cmd       string
+	broadcast int    // broadcast id, 0 for a command to the active server only
+	origin    string // where a broadcast was typed
+	line      string // the broadcast command as typed
+}
+
+// sendAll sends each server its commands in order, spaced out on
+// rate-limited servers. Different servers are sent to side by side, at most
+// max_concurrency at a time. Broadcasts start being tallied here, once it's
+// known how many of their commands are actually going out.
+func (m *model) sendAll(sends []outgoing) tea.Cmd {
+	var order []string
+	perServer := make(map[string][]tea.Cmd)
+	ctx, pool, slots := m.sendCtx, m.pool, m.sendSlots
+	for _, o := range sends {
+		o := o
+		// Retries happen inside the command, so the server's next one in
+		// the sequence still waits for this one to finish.
+		cmd := m.queueSend(o.server, retrying(ctx, o.server, func(attempt int) tea.Cmd {
+			return withBroadcast(o.broadcast, limited(slots, ctx, sendRCONAttempt(ctx, pool, o.server, o.cmd, attempt)))
+		}))
+		if o.broadcast != 0 {
+			t := m.broadcasts[o.broadcast]
+			if t == nil {
+				t = &broadcastTally{origin: o.origin, cmd: o.line, left: make(map[string]int), errs: make(map[string]error)}
+				m.broadcasts[o.broadcast] = t
+			}
+			if t.left[o.server.Name] == 0 {
+				t.pending++
+				t.total++
+			}
+			t.left[o.server.Name]++
+		}
+		if _, ok := perServer[o.server.Name]; !ok {
+			order = append(order, o.server.Name)
+		}
+		perServer[o.server.Name] = append(perServer[o.server.Name], cmd)
+	}
+	cmds := []tea.Cmd{m.sending(len(sends))}
+	for _, name := range order {
+		cmds = append(cmds, tea.Sequence(perServer[name]...))
+	}
+	return tea.Batch(cmds...)
+}
+
+// logDryRun logs exactly what submitInput would send to s for cmd, one
+// line per command an alias expands to.
+func (m *model) logDryRun(s serverConfig, cmd string) {
+	expanded, isAlias := expandAlias(cmd, s.Aliases, m.aliases)
+	for i, cmdStr := range expanded {
+		line := fmt.Sprintf("[%s] DRY-RUN: %s", s.Name, cmdStr)
+		if isAlias {
+			line = fmt.Sprintf("[%s] DRY-RUN: %s", s.Name, aliasStep(cmd, expanded, i))
+		}
+		if rule, ok := s.blockedBy(cmdStr); ok {
+			if s.Block {
+				line += fmt.Sprintf(" (would be refused: matches blocked command %q)", rule)
+			} else {
+				line += fmt.Sprintf(" (would ask first: matches blocked command %q)", rule)
+			}
+		}
+		m.pushLogFor(s.Name, logInfo, line)
+	}
+}
+
+// commands
+
+func sendRCONCmd(ctx context.Context, pool *connPool, s serverConfig, cmd string) tea.Cmd {
+	return sendRCONAttempt(ctx, pool, s, cmd, 1)
+}
+
+// sendRCONAttempt is sendRCONCmd for a given dial attempt. Connection-level
+// failures are marked retryable while attempts remain; retrying makes the
+// next attempt. Cancelling ctx abandons the command, closing its connection
+// if it is waiting on a response.
+func sendRCONAttempt(ctx context.Context, pool *connPool, s serverConfig, cmd string, attempt int) tea.Cmd {
+	return func() tea.Msg {
+		if err := ctx.Err(); err != nil {
+			return rconResultMsg{serverName: s.Name, cmd: cmd, err: err, attempt: attempt}
+		}
+		start := time.Now()
+		client, dialed, err := pool.get(s)
+		var (
+			dialTime time.Duration
+			endpoint string
+		)
+		if dialed {
+			dialTime = time.Since(start)
+			endpoint = pool.endpoint(s.Name)
+		}
+		if err != nil {
+			err = dialError(s, err)
+			retryable := !errors.Is(err, rcon.ErrAuthFailed) && !errors.Is(err, errNoPassword)
+			if retryable && attempt > 1 && attempt > s.Retries {
+				err = fmt.Errorf("%w (gave up after %d attempts)", err, attempt)
+			}
+			return rconResultMsg{
+				serverName: s.Name,
+				cmd:        cmd,
+				err:        err,
+				attempt:    attempt,
+				retryable:  retryable && attempt <= s.Retries,
+				dialed:     dialTime,
+			}
+		}
+
+		start = time.Now()
+		resp, err := execute(ctx, client, cmd, s.commandTimeout())
+		rtt := time.Since(start)
+		packets := packetsOf(client)
+		if err != nil {
+			pool.discard(s.Name, client)
+		} else {
+			pool.put(s.Name, client)
+		}
+		return rconResultMsg{
+			serverName: s.Name,
+			cmd:        cmd,
+			output:     resp,
+			err:        err,
+			rtt:        rtt,
+			attempt:    attempt,
+			dialed:     dialTime,
+			endpoint:   endpoint,
+			packets:    packets,
+		}
+	}
+}
+
+// retrying sends a command with send, which makes the given attempt, and
+// after a retryable failure backs off and makes the next attempt from inside
+// the same command. Each failed attempt is still delivered as it lands, so
+// it shows up in the log, but anything sequenced after the command waits
+// until the retries are over. Cancelling ctx cuts the backoff short.
+func retrying(ctx context.Context, s serverConfig, send func(attempt int) tea.Cmd) tea.Cmd {
+	return retryingFrom(ctx, s, send, 1)
+}
+
+func retryingFrom(ctx context.Context, s serverConfig, send func(attempt int) tea.Cmd, attempt int) tea.Cmd {
+	return func() tea.Msg {
+		res := send(attempt)().(rconResultMsg)
+		if !res.retryable {
+			return res
+		}
+		next := func() tea.Msg {
+			select {
+			case <-time.After(retryDelay(s, attempt)):
+			case <-ctx.Done():
+			}
+			return retryingFrom(ctx, s, send, attempt+1)()
+		}
+		return tea.Sequence(func() tea.Msg { return res }, next)()
+	}
+}
+
+// reconnect re-dials s, replacing whatever connection the pool held for it.
+func reconnect(pool *connPool, s serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		err := pool.replace(s)
+		if err != nil {
+			err = dialError(s, err)
+		}
+		return reconnectMsg{serverName: s.Name, endpoint: pool.endpoint(s.Name), err: err, took: time.Since(start)}
+	}
+}
+
+// waitForPoolChange delivers the pool's next connection change.
+func waitForPoolChange(pool *connPool) tea.Cmd {
+	return func() tea.Msg {
+		<-pool.changed
+		return poolChangedMsg{}
+	}
+}
+
+// errExecuteTimeout means the server took the command but never answered.
+var errExecuteTimeout = errors.New("execute timed out")
+
+// execute runs cmd on client, giving up after timeout even if the server
+// accepted the connection but never responds, or as soon as ctx is
+// cancelled. The caller discards client on error, and closing it unblocks
+// the abandoned Execute.
+func execute(ctx context.Context, client rconClient, cmd string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := client.Execute(cmd)
+		done <- result{out, err}
+	}()
+
+	select {
+	case r := <-done:
+		var netErr net.Error
+		if errors.As(r.err, &netErr) && netErr.Timeout() {
+			return "", fmt.Errorf("%w after %s", errExecuteTimeout, timeout)
+		}
+		return r.out, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return "", ctx.Err()
+		}
+		return "", fmt.Errorf("%w after %s", errExecuteTimeout, timeout)
+	}
+}
+
+// retryDelay is the exponential backoff before the given retry (1-based).
+func retryDelay(s serverConfig, retry int) time.Duration {
+	base := s.RetryDelay
+	if base <= 0 {
+		base = defaultRetryDelay
+	}
+	return base << (retry - 1)
+}
+
+// dialError rewords a failed dial so wrong passwords and unreachable
+// servers are easy to tell apart in the log.
+func dialError(s serverConfig, err error) error {
+	var failover failoverError
+	if errors.As(err, &failover) {
+		return fmt.Errorf("failed to connect to any address: %w", err)
+	}
+	if errors.Is(err, rcon.ErrAuthFailed) {
+		return fmt.Errorf("%w — check the password for %s", rcon.ErrAuthFailed, s.Name)
+	}
+	if errors.Is(err, errNoPassword) {
+		return fmt.Errorf("%w for %s — it is asked for when a command is typed to it", errNoPassword, s.Name)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		err = fmt.Errorf("connect timed out after %s", s.dialTimeout())
+	}
+	return fmt.Errorf("failed to connect: %w", err)
+}
+
+// tea.Model
+
+func (m model) Init() tea.Cmd {
+	if m.replay != nil {
+		// A replay only plays back what was recorded, so nothing here
+		// should reach the real servers.
+		return tea.Batch(textarea.Blink, statusTick(), m.startReplay())
+	}
+	return tea.Batch(
+		textarea.Blink,
+		pollReachability(m.servers),
+		pollPlayers(m.servers),
+		startSchedules(m.servers),
+		startKeepalives(m.servers),
+		statusTick(),
+		statsTick(),
+		waitForPoolChange(m.pool),
+		m.runInitScript(),
+	)
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.inline {
+			m.height = min(msg.Height, inlineHeight)
+		}
+		m.layout()
+		m.refreshLog()
+		return m, nil
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
+	case tea.KeyMsg:
+		m.watchAlert = ""
+		if m.showHelp || m.info != nil {
+			m.showHelp, m.info = false, nil
+			return m, nil
+		}
+		if m.prompt != nil {
+			return m, m.answerPrompt(msg)
+		}
+		if m.palette != nil {
+			return m.updatePalette(msg)
+		}
+		if m.password != nil {
+			return m.updatePassword(msg)
+		}
+		if m.tmplMenu != nil {
+			return m.updateTemplates(msg)
+		}
+		if s := msg.String(); s != "tab" && s != "shift+tab" {
+			m.completion = nil
+		}
+		if m.list.SettingFilter() && !m.keys.matches(msg, "quit") {
+			return m.updateFilter(msg)
+		}
+		if m.focus == focusInput && m.lineEditKey(msg) {
+			// Readline keys edit the command even where an action shares
+			// them, like Ctrl+E; the action still works from the other panes.
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		switch {
+		case m.keys.matches(msg, "cancel") && m.cancellable(),
+			m.keys.matches(msg, "quit") && m.cancellable() && !m.cancelled:
+			// A first Ctrl+C stops what's running; the next one quits.
+			m.cancelPending()
+			return m, nil
+		case m.keys.matches(msg, "quit"):
+			m.quitting = true
+			for _, st := range m.logStreams {
+				st.stop()
+			}
+			return m, tea.Quit
+		case m.keys.matches(msg, "help") && (m.focus != focusInput || m.input.Value() == ""):
+			// Only on an empty prompt, so "?" can still be typed into commands.
+			m.showHelp = true
+			return m, nil
+		case m.keys.matches(msg, "info") && (m.focus != focusInput || msg.Type != tea.KeyRunes):
+			// A plain letter only counts outside the input, so it can still be typed.
+			return m, m.openInfo()
+		case m.keys.matches(msg, "palette"):
+			m.openPalette()
+			return m, nil
+		case m.keys.matches(msg, "templates"):
+			m.openTemplates()
+			return m, nil
+		case msg.String() == "tab" && m.canComplete():
+			m.complete(1)
+			return m, nil
+		case msg.String() == "shift+tab" && m.canComplete():
+			m.complete(-1)
+			return m, nil
+		case msg.String() == "tab":
+			return m, m.setFocus((m.focus + 1) % focusCount)
+		case msg.String() == "shift+tab":
+			return m, m.setFocus((m.focus + focusCount - 1) % focusCount)
+		case m.keys.matches(msg, "switch"):
+			// Cycle the active server without leaving the current pane.
+			if idx, ok := m.nextServerIndex(); ok {
+				m.list.Select(idx)
+				if it, ok := m.list.SelectedItem().(serverItem); ok {
+					m.setActive(it.Name)
+				}
+			}
+			return m, nil
+		case m.keys.matches(msg, "start"):
+			// Docker start
+			s := m.dockerTarget()
 			if s == nil {
-				m.pushLog("❌ No active server selected.")
 				return m, nil
 			}
-			if s.Container == "" {
-				m.pushLog(fmt.Sprintf("[%s] ⚠️ No container configured", s.Name))
+			m.pushLog(logDocker, fmt.Sprintf("[%s] 🐳 Starting container: %s", s.Name, s.containerLabel()))
+			m.setStatus("Starting container...")
+			return m, m.runDocker(*s, "start")
+		case m.keys.matches(msg, "stop"):
+			// Docker stop
+			s := m.dockerTarget()
+			if s == nil {
 				return m, nil
 			}
-			m.pushLog(fmt.Sprintf("[%s] 🐳 Stopping container: %s", s.Name, s.Container))
-			m.setStatus("Stopping container...")
-			return m, dockerAction(*s, "stop")
-		case "ctrl+r":
+			return m, m.stopContainer(*s)
+		case m.keys.matches(msg, "restart"):
 			// Docker restart
-			s := m.activeServer()
+			s := m.dockerTarget()
 			if s == nil {
-				m.pushLog("❌ No active server selected.")
 				return m, nil
 			}
-			if s.Container == "" {
-				m.pushLog(fmt.Sprintf("[%s] ⚠️ No container configured", s.Name))
+			m.pushLog(logDocker, fmt.Sprintf("[%s] 🐳 Restarting container: %s", s.Name, s.containerLabel()))
+			m.setStatus("Restarting container...")
+			return m, m.runDocker(*s, "restart")
+		case m.keys.matches(msg, "pause"):
+			// Docker pause
+			s := m.dockerTarget()
+			if s == nil {
 				return m, nil
 			}
-			m.pushLog(fmt.Sprintf("[%s] 🐳 Restarting container: %s", s.Name, s.Container))
-			m.setStatus("Restarting container...")
-			return m, dockerAction(*s, "restart")
-		case "ctrl+d":
-			// Docker status
+			m.pushLog(logDocker, fmt.Sprintf("[%s] 🐳 Pausing container: %s", s.Name, s.containerLabel()))
+			m.setStatus("Pausing container...")
+			return m, m.runDocker(*s, "pause")
+		case m.keys.matches(msg, "unpause"):
+			// Docker unpause
+			s := m.dockerTarget()
+			if s == nil {
+				return m, nil
+			}
+			m.pushLog(logDocker, fmt.Sprintf("[%s] 🐳 Unpausing container: %s", s.Name, s.containerLabel()))
+			m.setStatus("Unpausing container...")
+			return m, m.runDocker(*s, "unpause")
+		case m.keys.matches(msg, "dryrun"):
+			m.dryRun = !m.dryRun
+			if m.dryRun {
+				m.pushLog(logInfo, "🧪 Dry-run on: commands are logged, not sent")
+			} else {
+				m.pushLog(logInfo, "🧪 Dry-run off")
+			}
+			return m, nil
+		case m.keys.matches(msg, "reconnect"):
 			s := m.activeServer()
 			if s == nil {
-				m.pushLog("❌ No active server selected.")
+				m.pushLog(logError, "❌ No active server selected.")
 				return m, nil
 			}
-			if s.Container == "" {
-				m.pushLog(fmt.Sprintf("[%s] ⚠️ No container configured", s.Name))
+			if m.replay != nil {
+				m.pushLog(logError, "❌ Nothing is reconnected while replaying a recording.")
 				return m, nil
 			}
-			m.pushLog(fmt.Sprintf("[%s] 🐳 Checking status: %s", s.Name, s.Container))
+			m.pushLog(logInfo, fmt.Sprintf("[%s] 🔌 Reconnecting to %s...", s.Name, s.Address.primary()))
+			m.setStatus("Reconnecting...")
+			return m, tea.Batch(m.sending(1), reconnect(m.pool, *s))
+		case m.keys.matches(msg, "status"):
+			// Docker status
+			s := m.dockerTarget()
+			if s == nil {
+				return m, nil
+			}
+			m.pushLog(logDocker, fmt.Sprintf("[%s] 🐳 Checking status: %s", s.Name, s.containerLabel()))
 			m.setStatus("Checking status...")
-			return m, dockerAction(*s, "status")
-		case "enter":
-			cmdStr := m.input.Value()
-			m.input.Reset()
-			if cmdStr == "" {
+			return m, m.runDocker(*s, "status")
+		case m.keys.matches(msg, "stats"):
+			// Docker stats
+			s := m.dockerTarget()
+			if s == nil {
 				return m, nil
 			}
-			s := m.activeServer()
+			m.setStatus("Fetching stats...")
+			return m, m.runDocker(*s, "stats")
+		case m.keys.matches(msg, "logs"):
+			// Docker logs, toggled per server. Streams keep running when
+			// switching servers, so several can be followed at once.
+			s := m.dockerTarget()
 			if s == nil {
-				m.pushLog("❌ No active server selected.")
 				return m, nil
 			}
-			m.pushLog(fmt.Sprintf("[%s] > %s", s.Name, cmdStr))
-			m.setStatus("Sending...")
-			return m, sendRCONCmd(*s, cmdStr)
+			if m.logStreams[s.Name] != nil {
+				m.stopLogStream(s.Name)
+				return m, nil
+			}
+			m.pushLog(logDocker, fmt.Sprintf("[%s] 🐳 Following logs: %s", s.Name, s.containerLabel()))
+			st, cmd := startLogStream(*s)
+			m.logStreams[s.Name] = st
+			if n := len(m.logStreams); n > 1 {
+				m.setStatus(fmt.Sprintf("Following %d container logs", n))
+			}
+			m.refreshLog()
+			return m, cmd
+		case m.keys.matches(msg, "export"):
+			path, err := m.exportLog()
+			if err != nil {
+				m.pushLog(logError, fmt.Sprintf("❌ Failed to export log: %v", err))
+				m.setStatus("Export failed")
+				return m, nil
+			}
+			m.setStatus("Log exported to " + path)
+			return m, nil
+		case m.keys.matches(msg, "bottom") && (m.focus == focusLog || msg.Type != tea.KeyRunes):
+			// A plain letter only counts in the log, so it can still be typed.
+			m.viewport.GotoBottom()
+			return m, nil
+		case m.keys.matches(msg, "reload") && m.replay == nil:
+			m.setStatus("Reloading config...")
+			return m, reloadConfig(m.cfgPaths)
+		case m.keys.matches(msg, "expand"):
+			if !m.expandResponse() {
+				m.setStatus("No cut-off response to expand")
+			}
+			return m, nil
+		case m.keys.matches(msg, "clear"):
+			name := m.activeName
+			m.confirm(fmt.Sprintf("[%s] Clear the log?", name), func(m *model) tea.Cmd {
+				m.logs[name] = nil
+				if name == m.activeName {
+					m.refreshLog()
+					m.viewport.GotoTop()
+				}
+				return nil
+			}, nil)
+			return m, nil
+		case msg.String() == "pgup" || msg.String() == "pgdown":
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+
+		switch m.focus {
+		case focusList:
+			return m.updateList(msg)
+		case focusLog:
+			if msg.String() == "esc" {
+				return m, m.setFocus(focusInput)
+			}
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		default:
+			return m.updateInput(msg)
 		}
 
 	case rconResultMsg:
-		if msg.err != nil {
-			m.pushLog(fmt.Sprintf("[%s] ⚠️ ERROR: %v", msg.serverName, msg.err))
+		m.logDial(msg)
+		m.noteEndpoint(msg.serverName, msg.endpoint)
+		if errors.Is(msg.err, rcon.ErrAuthFailed) {
+			m.forgetPassword(msg.serverName)
+		}
+		m.logPackets(msg)
+		if !msg.retryable {
+			m.record(rconEvent(msg))
+		}
+		if msg.retryable {
+			if s := m.serverByName(msg.serverName); s != nil {
+				delay := retryDelay(*s, msg.attempt)
+				m.pushLogFor(s.Name, logWarn, fmt.Sprintf("[%s] 🔁 %v — retry %d/%d in %s", s.Name, msg.err, msg.attempt, s.Retries, delay))
+				m.setStatus("Retrying...")
+			}
+			// The command that sent it is already making the next attempt.
+			return m, nil
+		}
+		m.landed()
+		var cmd tea.Cmd
+		if errors.Is(msg.err, context.Canceled) {
+			m.pushLogFor(msg.serverName, logWarn, fmt.Sprintf("[%s] 🚫 [%s] Cancelled", msg.serverName, msg.cmd))
+			m.setStatus("Cancelled")
+		} else if msg.err != nil {
+			m.pushLogFor(msg.serverName, logError, fmt.Sprintf("[%s] ⚠️ [%s] ERROR: %v", msg.serverName, msg.cmd, msg.err))
 			m.setStatus("Command failed")
+			cmd = m.notify(fmt.Sprintf("⚠️ **%s**: `%s` failed: %v", msg.serverName, msg.cmd, msg.err))
 		} else {
-			out := msg.output
-			if out == "" {
-				out = "(no response)"
+			m.pushLogFor(msg.serverName, logResponse, responseText(msg.serverName, msg.cmd, msg.output))
+			m.setStatus(fmt.Sprintf("OK (%dms)", msg.rtt.Milliseconds()))
+			m.lastOK[msg.serverName] = time.Now()
+			if s := m.serverByName(msg.serverName); s != nil {
+				if names, ok := parsePlayerList(s.Type, msg.cmd, s.plainResponse(msg.output)); ok {
+					m.playerLists[s.Name] = names
+					m.layout()
+				}
+			}
+		}
+		m.countBroadcast(msg)
+		if msg.batch != nil && msg.batch == m.batch {
+			return m, tea.Batch(cmd, m.scriptResult(msg))
+		}
+		return m, cmd
+
+	case spinner.TickMsg:
+		if m.inFlight == 0 {
+			return m, nil // let the tick loop end
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case reconnectMsg:
+		m.landed()
+		if msg.err != nil {
+			m.pushLogFor(msg.serverName, logError, fmt.Sprintf("[%s] ⚠️ Reconnect failed: %v", msg.serverName, msg.err))
+			m.setStatus("Reconnect failed")
+			return m, nil
+		}
+		m.pushLogFor(msg.serverName, logInfo, fmt.Sprintf("[%s] 🔌 Reconnected to %s", msg.serverName, msg.endpoint))
+		m.noteEndpoint(msg.serverName, msg.endpoint)
+		m.setStatus(fmt.Sprintf("Reconnected (%dms)", msg.took.Milliseconds()))
+		return m, nil
+
+	case stopCommandsMsg:
+		m.landed()
+		return m, m.stopCommandsDone(msg)
+
+	case statsTickMsg:
+		return m, m.pollStats()
+
+	case statsSampleMsg:
+		// Failed samples are skipped quietly; the stats action reports errors.
+		if msg.err == nil {
+			ss := m.statHistory[msg.serverName]
+			if ss == nil {
+				ss = &statsSeries{}
+				m.statHistory[msg.serverName] = ss
+			}
+			ss.add(msg.cpu, msg.mem)
+			m.layout()
+		}
+		return m, statsTick()
+
+	case poolChangedMsg:
+		m.rebuildList()
+		return m, waitForPoolChange(m.pool)
+
+	case replayMsg:
+		return m, m.replayEvent(msg.index)
+
+	case replayCommandMsg:
+		if msg.expanded != "" {
+			m.pushLogFor(msg.serverName, logCommand, fmt.Sprintf("[%s] > %s → %s", msg.serverName, msg.cmd, msg.expanded))
+		} else {
+			m.pushLogFor(msg.serverName, logCommand, fmt.Sprintf("[%s] > %s", msg.serverName, msg.cmd))
+		}
+		return m, nil
+
+	case stopGraceMsg:
+		if s := m.serverByName(msg.serverName); s != nil {
+			return m, m.dockerStop(*s)
+		}
+		return m, nil
+
+	case runScriptMsg:
+		return m, m.startScript(msg)
+
+	case dockerLogLineMsg:
+		name := msg.stream.serverName
+		if msg.stream != m.logStreams[name] {
+			return m, nil
+		}
+		// Each line stays in its own server's log; while the active server
+		// is followed too, logLines interleaves them by arrival order.
+		m.pushLogLine(name, fmt.Sprintf("[%s] 📜 %s", name, msg.line))
+		return m, waitForLogLine(msg.stream)
+
+	case dockerLogEndMsg:
+		name := msg.stream.serverName
+		if msg.stream != m.logStreams[name] {
+			return m, nil
+		}
+		delete(m.logStreams, name)
+		if msg.err != nil {
+			m.pushLogFor(name, logError, fmt.Sprintf("[%s] 🐳 ERROR: logs: %v", name, msg.err))
+		} else {
+			m.pushLogFor(name, logDocker, fmt.Sprintf("[%s] 🐳 Log stream ended", name))
+		}
+		m.refreshLog()
+		return m, nil
+
+	case scheduleTickMsg:
+		for _, s := range m.servers {
+			if s.Name != msg.serverName || msg.index >= len(s.Schedule) {
+				continue
 			}
-			m.pushLog(fmt.Sprintf("[%s] < %s", msg.serverName, out))
-			m.setStatus("OK")
+			e := s.Schedule[msg.index]
+			if e.Interval <= 0 || e.Command == "" {
+				// A reload turned the entry off; let its loop end here.
+				return m, nil
+			}
+			m.pushLogFor(s.Name, logCommand, fmt.Sprintf("[%s] ⏰ > %s", s.Name, e.Command))
+			ctx, pool := m.sendCtx, m.pool
+			return m, tea.Batch(
+				m.sending(1),
+				m.queueSend(s, retrying(ctx, s, func(attempt int) tea.Cmd {
+					return sendRCONAttempt(ctx, pool, s, e.Command, attempt)
+				})),
+				scheduleTick(s.Name, msg.index, e.Interval),
+			)
+		}
+		return m, nil
+
+	case configReloadMsg:
+		if msg.err != nil {
+			m.pushLog(logError, fmt.Sprintf("❌ Config reload failed, keeping the running config: %v", msg.err))
+			m.setStatus("Config reload failed")
+			return m, nil
+		}
+		for _, w := range msg.warnings {
+			m.pushLog(logWarn, "⚠️ "+w)
+		}
+		for _, w := range m.applyGlobals(msg.cfg) {
+			m.pushLog(logWarn, "⚠️ "+w)
+		}
+		cmd, summary := m.applyServers(msg.cfg.Servers)
+		m.pushLog(logInfo, "🔄 Config reloaded: "+summary)
+		m.setStatus("Config reloaded")
+		return m, cmd
+
+	case keepaliveTickMsg:
+		if s := m.serverByName(msg.serverName); s != nil && s.Keepalive > 0 {
+			return m, sendKeepalive(m.pool, *s)
+		}
+		return m, nil
+
+	case keepaliveMsg:
+		m.logKeepalive(msg)
+		if s := m.serverByName(msg.serverName); s != nil && s.Keepalive > 0 {
+			return m, keepaliveTick(*s)
 		}
 		return m, nil
 
+	case pollTickMsg:
+		cmds := []tea.Cmd{pollReachability(m.servers), pollPlayers(m.servers)}
+		if m.webhook != "" && !m.noDocker {
+			// Container states are only watched to report exits.
+			cmds = append(cmds, pollContainers(m.servers))
+		}
+		return m, tea.Batch(cmds...)
+
+	case containerPollMsg:
+		var cmds []tea.Cmd
+		for name, state := range msg.states {
+			cmds = append(cmds, m.setContainerState(name, state))
+		}
+		return m, tea.Batch(cmds...)
+
+	case statusTickMsg:
+		if m.statusLine != "" && time.Since(m.statusTimer) > statusTimeout {
+			m.statusLine = ""
+		}
+		if m.logFile != nil {
+			if err := m.logFile.flush(); err != nil {
+				m.logFile.close()
+				m.logFile = nil
+				m.pushLog(logError, fmt.Sprintf("❌ Log file disabled: %v", err))
+			}
+		}
+		return m, statusTick()
+
+	case infoResolvedMsg:
+		if m.info != nil && m.info.serverName == msg.serverName {
+			m.info.resolving, m.info.resolved, m.info.resolveErr = false, msg.addrs, msg.err
+		}
+		return m, nil
+
+	case notifyResultMsg:
+		if msg.err != nil {
+			m.pushLog(logWarn, fmt.Sprintf("⚠️ Discord notification failed: %v", msg.err))
+		}
+		return m, nil
+
+	case statusPollMsg:
+		for name, state := range msg.reach {
+			m.reach[name] = state
+		}
+		m.rebuildList()
+		return m, schedulePoll(m.pollEvery)
+
+	case playerPollMsg:
+		for _, s := range m.servers {
+			if s.queryAddress() == "" {
+				continue
+			}
+			delete(m.players, s.Name)
+			if pc, ok := msg.counts[s.Name]; ok {
+				m.players[s.Name] = &pc
+			}
+		}
+		m.rebuildList()
+		return m, nil
+
 	case dockerResultMsg:
+		m.landed()
+		m.record(dockerEvent(msg))
+		if msg.action == "stats" && msg.err == nil {
+			st, err := parseContainerStats(msg.output)
+			if err != nil {
+				m.pushLogFor(msg.serverName, logError, fmt.Sprintf("[%s] 🐳 ERROR: stats: %v", msg.serverName, err))
+				m.setStatus("Docker stats failed")
+				return m, nil
+			}
+			m.stats[msg.serverName] = st
+			m.setStatus(fmt.Sprintf("[%s] %s", msg.serverName, st))
+			return m, nil
+		}
+		var cmd tea.Cmd
 		if msg.err != nil {
-			m.pushLog(fmt.Sprintf("[%s] 🐳 ERROR: %v", msg.serverName, msg.err))
+			m.pushLogFor(msg.serverName, logError, fmt.Sprintf("[%s] 🐳 ERROR: %v", msg.serverName, msg.err))
 			m.setStatus(fmt.Sprintf("Docker %s failed", msg.action))
+			cmd = m.notify(fmt.Sprintf("🐳 **%s**: docker %s failed: %v", msg.serverName, msg.action, msg.err))
 		} else {
 			out := msg.output
 			if out == "" {
 				out = "success"
 			}
-			m.pushLog(fmt.Sprintf("[%s] 🐳 %s: %s", msg.serverName, msg.action, out))
-			m.setStatus(fmt.Sprintf("Docker %s OK", msg.action))
+			if msg.action == "status" {
+				state := parseContainerState(msg.output)
+				out = state.details()
+				m.setStatus(fmt.Sprintf("[%s] Container %s", msg.serverName, state.badge()))
+				cmd = m.setContainerState(msg.serverName, state)
+			} else {
+				m.setStatus(fmt.Sprintf("Docker %s OK", msg.action))
+			}
+			m.pushLogFor(msg.serverName, logDocker, fmt.Sprintf("[%s] 🐳 %s: %s", msg.serverName, msg.action, out))
 		}
-		return m, nil
+		return m, cmd
 	}
 
 	var cmdInput, cmdList tea.Cmd
@@ -356,61 +1894,397 @@ func (m model) View() string {
 	if m.quitting {
 		return ""
 	}
-
-	leftWidth := 24
-	rightWidth := m.width - leftWidth - 2
-	if rightWidth < 40 {
-		rightWidth = 40
+	if m.tooSmall() {
+		msg := fmt.Sprintf("Terminal too small\n%dx%d, need at least %dx%d", m.width, m.height, minWidth, minHeight)
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+			statusStyle.Align(lipgloss.Center).Render(msg))
+	}
+	if m.showHelp {
+		return m.helpView()
+	}
+	if m.palette != nil {
+		return m.paletteView()
+	}
+	if m.info != nil {
+		return m.infoView()
+	}
+	if m.password != nil {
+		return m.passwordView()
+	}
+	if m.tmplMenu != nil {
+		return m.templatesView()
 	}
 
-	listView := lipgloss.NewStyle().Width(leftWidth).Render(m.list.View())
+	rightWidth := m.logWidth()
+	mainHeight := atLeast(m.height-panelHeight, minLogHeight+2)
 
-	logStyle := lipgloss.NewStyle().Width(rightWidth).Height(m.height - 6)
-	logContent := ""
-	start := 0
-	if len(m.logLines) > m.height-6 {
-		start = len(m.logLines) - (m.height - 6)
-	}
-	for i := start; i < len(m.logLines); i++ {
-		logContent += m.logLines[i] + "\n"
+	listView := panel("Servers", lipgloss.JoinVertical(lipgloss.Left, m.list.View(), m.playerPanel()),
+		listWidth, mainHeight, m.focus == focusList)
+
+	logRows := []string{m.tabBar(rightWidth)}
+	if graph := m.resourceGraph(rightWidth); graph != "" {
+		logRows = append(logRows, graph)
 	}
-	logView := logStyle.Render(logContent)
+	logView := panel("Log", lipgloss.JoinVertical(lipgloss.Left, append(logRows, m.viewport.View())...),
+		rightWidth, mainHeight, m.focus == focusLog)
 
 	status := m.statusLine
+	if m.watchAlert != "" {
+		status = logStyles[logWarn].Render(m.watchAlert)
+	}
 	if status == "" {
 		if s := m.activeServer(); s != nil {
-			status = fmt.Sprintf("Active: %s (%s)", s.Name, s.Address)
-			if s.Container != "" {
-				status += fmt.Sprintf(" | Container: %s", s.Container)
+			status = fmt.Sprintf("Active: %s (%s)", s.Name, s.Address.primary())
+			if addr := m.pool.endpoint(s.Name); s.isBackup(addr) {
+				status = fmt.Sprintf("Active: %s (via backup %s)", s.Name, addr)
+			}
+			if pc := m.players[s.Name]; pc != nil {
+				if pc.mapName != "" {
+					status += " | Map: " + pc.mapName
+				}
+				status += fmt.Sprintf(" | Players: %d/%d", pc.online, pc.max)
+			}
+			if s.hasContainer() {
+				status += fmt.Sprintf(" | Container: %s", s.containerLabel())
+				if state, ok := m.containers[s.Name]; ok {
+					status += " " + state.badge()
+				}
+				if st, ok := m.stats[s.Name]; ok {
+					status += " | " + st.String()
+				}
+			}
+			if n := m.queued(s.Name); n > 0 {
+				status += fmt.Sprintf(" | Queued: %d", n)
 			}
 		} else {
 			status = "No active server"
 		}
 	}
-	helpText := " [Tab] switch | [Ctrl+S] start | [Ctrl+X] stop | [Ctrl+R] restart | [Ctrl+D] status | [Ctrl+C] quit"
-	statusBar := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(status + "\n" + helpText)
+	if m.inFlight > 0 {
+		pending := fmt.Sprintf("(%d pending)", m.inFlight)
+		if m.inFlight >= pendingWarnAt {
+			pending = logStyles[logWarn].Render(pending)
+		}
+		status = m.spinner.View() + " " + pending + " " + status
+	}
+	if m.prompt != nil {
+		status = m.prompt.render()
+	}
+	helpText := m.keys.footer()
+	statusBar := statusStyle.Render(status + "\n" + helpText)
 
-	inputView := lipgloss.NewStyle().Width(rightWidth).Render(m.input.View())
-	mainRow := lipgloss.JoinHorizontal(lipgloss.Top, listView, " ", logView)
+	inputTitle := "Command"
+	if m.dryRun {
+		inputTitle += " (DRY-RUN)"
+	}
+	inputView := panel(inputTitle, m.input.View(), atLeast(m.width-2, minWidth-2), inputHeight, m.focus == focusInput)
+	mainRow := lipgloss.JoinHorizontal(lipgloss.Top, listView, logView)
 
-	return lipgloss.JoinVertical(lipgloss.Left, mainRow, statusBar, inputView)
+	view := lipgloss.JoinVertical(lipgloss.Left, mainRow, statusBar, inputView)
+	if m.completion != nil {
+		// The candidates pop up just above the input, over the status bar
+		// and the bottom of the panes.
+		box := m.completion.popup(m.width)
+		view = overlay(view, box, 0, lipgloss.Height(mainRow)+lipgloss.Height(statusBar)-lipgloss.Height(box))
+	}
+	return view
+}
+
+// runExec sends a single command, or with a script every command in it, to
+// the selected servers and prints the responses, returning the process exit
+// code. selector is a server name, "@tag:name" or a name glob; with several
+// servers they run one after another and each output line is prefixed with
+// its server's name. With jsonOut, each result is printed as one execResult
+// line instead.
+func runExec(cfg appConfig, selector, cmd string, script []string, continueOnError, jsonOut bool) int {
+	targets, err := selectServers(cfg.Servers, selector)
+	if err != nil {
+		if jsonOut {
+			printJSON(execResult{Server: selector, Command: cmd, Error: err.Error()})
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 2
+	}
+	if cmd == "" && script == nil {
+		fmt.Fprintln(os.Stderr, "usage: bubblecon -exec <server> <command>")
+		return 2
+	}
+
+	pool := newConnPool(cfg.IdleTimeout)
+	defer pool.closeAll()
+
+	labeled := len(targets) > 1
+	code := 0
+	for _, target := range targets {
+		if c := execOn(cfg, pool, target, cmd, script, continueOnError, jsonOut, labeled); c > code {
+			code = c
+		}
+	}
+	return code
+}
+
+// execOn runs runExec's command or script on one server.
+func execOn(cfg appConfig, pool *connPool, target serverConfig, cmd string, script []string, continueOnError, jsonOut, labeled bool) int {
+	if script == nil {
+		if execCommand(cfg, pool, target, cmd, jsonOut, labeled) != nil {
+			return 1
+		}
+		return 0
+	}
+
+	failed := 0
+	for i, line := range script {
+		if i > 0 {
+			time.Sleep(target.sendInterval())
+		}
+		fmt.Fprintf(os.Stderr, "[%s] Running %d/%d: %s\n", target.Name, i+1, len(script), line)
+		if execCommand(cfg, pool, target, line, jsonOut, labeled) != nil {
+			failed++
+			if !continueOnError {
+				fmt.Fprintf(os.Stderr, "[%s] script aborted at %d/%d\n", target.Name, i+1, len(script))
+				return 1
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "[%s] %d of %d commands failed\n", target.Name, failed, len(script))
+		return 1
+	}
+	return 0
+}
+
+// execResult is the -json form of one -exec result.
+type execResult struct {
+	Server  string `json:"server"`
+	Command string `json:"command"`
+	Output  string `json:"output"`
+	Error   any    `json:"error"` // null on success
+}
+
+func printJSON(v execResult) {
+	b, _ := json.Marshal(v)
+	fmt.Println(string(b))
+}
+
+// execCommand sends a command for -exec, or each command of the alias it
+// names in turn, stopping at the first that fails.
+func execCommand(cfg appConfig, pool *connPool, target serverConfig, cmd string, jsonOut, labeled bool) error {
+	cmds, _ := expandAlias(cmd, target.Aliases, cfg.Aliases)
+	for _, c := range cmds {
+		if err := execOne(pool, target, c, jsonOut, labeled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execOne sends one command for -exec, retrying in place, and prints the
+// response or the error, labeling each response line with the server when
+// labeled is set.
+func execOne(pool *connPool, target serverConfig, cmd string, jsonOut, labeled bool) error {
+	res := sendRCONCmd(context.Background(), pool, target, cmd)().(rconResultMsg)
+	for res.retryable {
+		delay := retryDelay(target, res.attempt)
+		fmt.Fprintf(os.Stderr, "[%s] %v — retry %d/%d in %s\n", res.serverName, res.err, res.attempt, target.Retries, delay)
+		time.Sleep(delay)
+		res = sendRCONAttempt(context.Background(), pool, target, cmd, res.attempt+1)().(rconResultMsg)
+	}
+	for _, p := range res.packets {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", res.serverName, p)
+	}
+	if jsonOut {
+		out := execResult{Server: res.serverName, Command: cmd, Output: target.plainResponse(res.output)}
+		if res.err != nil {
+			out.Error = res.err.Error()
+		}
+		printJSON(out)
+		return res.err
+	}
+	if res.err != nil {
+		fmt.Fprintf(os.Stderr, "[%s] ERROR: %v\n", res.serverName, res.err)
+		return res.err
+	}
+	out := target.plainResponse(res.output)
+	if labeled {
+		out = "[" + target.Name + "] " + strings.ReplaceAll(out, "\n", "\n["+target.Name+"] ")
+	}
+	fmt.Println(out)
+	return nil
 }
 
 func main() {
-	cfgPath := "config.yaml"
-	servers, err := loadConfig(cfgPath)
+	var cfgPaths configPaths
+	flag.Var(&cfgPaths, "config", "config `file` to load (repeatable; later files win); defaults to $BUBBLECON_CONFIG, then ~/.config/bubblecon/config.yaml, then ./config.yaml")
+	profile := flag.String("profile", "", "load ~/.config/bubblecon/`name`.yaml instead of config.yaml; any -config files are loaded on top")
+	listProfilesFlag := flag.Bool("list-profiles", false, "list the profiles in the config's profiles setting and ~/.config/bubblecon, then exit")
+	execServer := flag.String("exec", "", "send a single command to `server` (a name, @tag:name or name glob) and exit; the command follows as arguments")
+	scriptPath := flag.String("script", "", "run the commands in `file` one at a time on the active server, or the -exec server")
+	continueOnError := flag.Bool("continue-on-error", false, "keep running a -script after a command fails")
+	jsonOut := flag.Bool("json", false, "print -exec results as JSON, one object per command")
+	recordPath := flag.String("record", "", "record every command, response and docker result to `file` as JSONL")
+	replayPath := flag.String("replay", "", "play back a -record `file` instead of talking to the servers")
+	replayInstant := flag.Bool("replay-instant", false, "play a -replay back without its recorded pauses")
+	themeName := flag.String("theme", "", "use the built-in `theme` (dark or light) instead of the config's")
+	inline := flag.Bool("inline", false, "draw below the shell prompt, keeping earlier output visible, instead of taking over the screen")
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	debug := flag.Bool("debug", false, "log the id, type and size of every RCON packet sent and received (TCP RCON only); implies log_level verbose")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println("bubblecon " + versionString())
+		os.Exit(0)
+	}
+	if *recordPath != "" && *replayPath != "" {
+		log.Println("⚠️ -record and -replay can't be used together.")
+		os.Exit(1)
+	}
+
+	if *listProfilesFlag {
+		paths := cfgPaths
+		if len(paths) == 0 {
+			path, _ := defaultConfig()
+			paths = configPaths{path}
+		}
+		if err := listProfiles(paths); err != nil {
+			log.Printf("⚠️ %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if *profile != "" {
+		path, err := profilePath(*profile)
+		if err != nil {
+			log.Printf("⚠️ %v\n", err)
+			os.Exit(1)
+		}
+		cfgPaths = append(configPaths{path}, cfgPaths...)
+	}
+	source := "-config"
+	if *profile != "" {
+		source = "-profile"
+	}
+	if len(cfgPaths) == 0 {
+		var path string
+		path, source = defaultConfig()
+		cfgPaths = configPaths{path}
+	}
+	cfg, warnings, err := loadConfigs(cfgPaths)
+	if path, missing := missingConfig(err); missing {
+		wrote, werr := offerStarterConfig(path)
+		if werr != nil {
+			log.Printf("⚠️ %v\n", werr)
+			os.Exit(1)
+		}
+		if wrote {
+			log.Printf("✅ Wrote %s. Fill in your server's address and RCON password, then run bubblecon again.\n", path)
+			os.Exit(0)
+		}
+	}
 	if err != nil {
 		log.Printf("⚠️ %v\n", err)
-		log.Println("Tip: Ensure config.yaml exists and defines at least one server.")
+		log.Println("Tip: Ensure the config file exists and defines at least one server.")
 		os.Exit(1)
 	}
 
-	if len(servers) == 0 {
-		log.Println("⚠️ No servers found in config.yaml. Exiting.")
+	if len(cfg.Servers) == 0 {
+		log.Println("⚠️ No servers found in config. Exiting.")
 		os.Exit(1)
 	}
 
-	if _, err := tea.NewProgram(initialModel(servers), tea.WithAltScreen()).Run(); err != nil {
+	if *themeName != "" {
+		cfg.Theme = themeConfig{Base: *themeName}
+	}
+	if *debug {
+		cfg.LogLevel = "verbose"
+		for i := range cfg.Servers {
+			cfg.Servers[i].tracePackets = true
+		}
+	}
+	t, err := cfg.Theme.resolve()
+	if err != nil {
+		log.Printf("⚠️ %v\n", err)
+		os.Exit(1)
+	}
+	applyTheme(t)
+
+	var script []string
+	if *scriptPath != "" {
+		if script, err = loadScript(*scriptPath); err != nil {
+			log.Printf("⚠️ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *execServer != "" {
+		for _, w := range warnings {
+			log.Printf("⚠️ %s\n", w)
+		}
+		args := flag.Args()
+		// Flag parsing stops at the command, so also accept a trailing
+		// -json, as in `-exec Survival list -json`.
+		for len(args) > 0 && (args[len(args)-1] == "-json" || args[len(args)-1] == "--json") {
+			*jsonOut = true
+			args = args[:len(args)-1]
+		}
+		os.Exit(runExec(cfg, *execServer, strings.Join(args, " "), script, *continueOnError, *jsonOut))
+	}
+
+	var replay []recordEvent
+	if *replayPath != "" {
+		if replay, err = loadRecording(*replayPath); err != nil {
+			log.Printf("⚠️ %v\n", err)
+			os.Exit(1)
+		}
+		cfg.DiscordWebhook = ""
+	}
+
+	pool := newConnPool(cfg.IdleTimeout)
+	m := initialModel(cfg, pool)
+	m.pushLog(logInfo, fmt.Sprintf("Config: %s (from %s)", strings.Join(cfgPaths, ", "), source))
+	for _, w := range warnings {
+		m.pushLog(logWarn, "⚠️ "+w)
+	}
+	if script != nil {
+		m.initScript = &runScriptMsg{path: *scriptPath, cmds: script, continueOnError: *continueOnError}
+	}
+	if *recordPath != "" {
+		if m.recorder, err = newRecorder(*recordPath); err != nil {
+			log.Printf("⚠️ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	m.replay, m.replayInstant = replay, *replayInstant
+	m.cfgPaths, m.tracePackets = cfgPaths, *debug
+	m.themeFlag = *themeName
+	m.inline = *inline
+	// Mouse reporting is left off inline: clicks can't be mapped to the
+	// view there, and the terminal keeps its own scrollback and selection.
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if m.inline {
+		opts = nil
+	}
+	final, err := tea.NewProgram(m, opts...).Run()
+	pool.closeAll()
+	if m, ok := final.(model); ok {
+		if err := saveHistory(m.histories); err != nil {
+			log.Printf("⚠️ failed to save command history: %v\n", err)
+		}
+		if err := saveActiveServer(m.activeName); err != nil {
+			log.Printf("⚠️ failed to save the active server: %v\n", err)
+		}
+		if m.logFile != nil {
+			if err := m.logFile.close(); err != nil {
+				log.Printf("⚠️ failed to flush log file: %v\n", err)
+			}
+		}
+		if m.recorder != nil {
+			if err := m.recorder.close(); err != nil {
+				log.Printf("⚠️ failed to close recording: %v\n", err)
+			}
+		}
+	}
+	if err != nil {
 		log.Println("Error:", err)
 		os.Exit(1)
 	}
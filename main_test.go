@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckHostPort(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr string // substring of the error, "" for none
+	}{
+		{"127.0.0.1:25575", ""},
+		{"mc.example.com:25575", ""},
+		{"[2001:db8::1]:25575", ""},
+		{"[::1]:8080", ""},
+		{"2001:db8::1", "e.g. [2001:db8::1]:port"},
+		{"2001:db8::1:25575", "e.g. [2001:db8::1]:25575"},
+		{"::1:8080", "e.g. [::1:8080]:port"},
+		{"mc.example.com", "missing port"},
+		{"127.0.0.1:0", "between 1 and 65535"},
+		{"127.0.0.1:rcon", "between 1 and 65535"},
+	}
+	for _, tt := range tests {
+		err := checkHostPort(tt.addr)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("checkHostPort(%q) = %v, want nil", tt.addr, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("checkHostPort(%q) = %v, want an error containing %q", tt.addr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUnbracketedIPv6(t *testing.T) {
+	tests := []struct {
+		addr  string
+		fixed string
+		ok    bool
+	}{
+		{"2001:db8::1", "[2001:db8::1]:port", true},
+		{"2001:db8::1:25575", "[2001:db8::1]:25575", true},
+		{"::1:8080", "[::1:8080]:port", true},
+		{"[2001:db8::1]:25575", "", false},
+		{"[2001:db8::1]", "", false},
+		{"127.0.0.1:25575", "", false},
+		{"127.0.0.1", "", false},
+		{"mc.example.com", "", false},
+	}
+	for _, tt := range tests {
+		fixed, ok := unbracketedIPv6(tt.addr)
+		if fixed != tt.fixed || ok != tt.ok {
+			t.Errorf("unbracketedIPv6(%q) = %q, %v, want %q, %v", tt.addr, fixed, ok, tt.fixed, tt.ok)
+		}
+	}
+}
+
+func TestBareHost(t *testing.T) {
+	tests := []struct {
+		addr string
+		host string
+		ok   bool
+	}{
+		{"mc.example.com", "mc.example.com", true},
+		{"127.0.0.1", "127.0.0.1", true},
+		{"2001:db8::1", "2001:db8::1", true},
+		{"[2001:db8::1]", "2001:db8::1", true},
+		{"::1:8080", "::1:8080", true},
+		{"[2001:db8::1]:25575", "", false},
+		{"127.0.0.1:25575", "", false},
+	}
+	for _, tt := range tests {
+		host, ok := bareHost(tt.addr)
+		if host != tt.host || ok != tt.ok {
+			t.Errorf("bareHost(%q) = %q, %v, want %q, %v", tt.addr, host, ok, tt.host, tt.ok)
+		}
+	}
+}
+
+func TestFillDefaultPorts(t *testing.T) {
+	tests := []struct {
+		addr     string
+		want     string
+		warnings int
+	}{
+		{"mc.example.com", "mc.example.com:25575", 1},
+		{"2001:db8::1", "[2001:db8::1]:25575", 1},
+		{"[2001:db8::1]", "[2001:db8::1]:25575", 1},
+		{"::1:8080", "[::1:8080]:25575", 1},
+		{"[2001:db8::1]:25576", "[2001:db8::1]:25576", 0},
+		{"2001:db8::1:25576", "2001:db8::1:25576", 0}, // left for checkHostPort to report
+	}
+	for _, tt := range tests {
+		s := serverConfig{Name: "test", Type: "minecraft", Address: addressList{tt.addr}}
+		warnings := s.fillDefaultPorts()
+		if !slices.Equal(s.Address, addressList{tt.want}) || len(warnings) != tt.warnings {
+			t.Errorf("fillDefaultPorts(%q) = %q with %d warnings, want %q with %d", tt.addr, s.Address, len(warnings), tt.want, tt.warnings)
+		}
+	}
+}
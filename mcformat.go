@@ -0,0 +1,59 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minecraft formatting codes
+
+var sectionSignPattern = regexp.MustCompile(`§[0-9a-fk-orA-FK-OR]?`)
+
+// mcColors maps the §0-§f color codes to the colors the game uses.
+var mcColors = map[byte]lipgloss.Color{
+	'0': "#000000", '1': "#0000AA", '2': "#00AA00", '3': "#00AAAA",
+	'4': "#AA0000", '5': "#AA00AA", '6': "#FFAA00", '7': "#AAAAAA",
+	'8': "#555555", '9': "#5555FF", 'a': "#55FF55", 'b': "#55FFFF",
+	'c': "#FF5555", 'd': "#FF55FF", 'e': "#FFFF55", 'f': "#FFFFFF",
+}
+
+// stripFormatting removes § codes, leaving the plain text.
+func stripFormatting(s string) string {
+	return sectionSignPattern.ReplaceAllString(s, "")
+}
+
+// renderFormatting translates § codes into lipgloss styling, starting from
+// base. As in the game, a color code also clears bold, italic and the other
+// decorations, and §r returns to base.
+func renderFormatting(s string, base lipgloss.Style) string {
+	var b strings.Builder
+	style := base
+	for i, part := range strings.Split(s, "§") {
+		if i > 0 && part != "" {
+			code := strings.ToLower(part[:1])[0]
+			switch {
+			case mcColors[code] != "":
+				style = base.Foreground(mcColors[code])
+			case code == 'l':
+				style = style.Bold(true)
+			case code == 'm':
+				style = style.Strikethrough(true)
+			case code == 'n':
+				style = style.Underline(true)
+			case code == 'o':
+				style = style.Italic(true)
+			case code == 'r':
+				style = base
+			}
+			if strings.ContainsRune("0123456789abcdefklmnor", rune(code)) {
+				part = part[1:] // §k (obfuscated) has no terminal equivalent and is dropped
+			}
+		}
+		if part != "" {
+			b.WriteString(style.Render(part))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// discord notifications
+
+const (
+	notifyTimeout    = 10 * time.Second
+	discordMaxLength = 2000 // Discord rejects longer message content
+)
+
+type notifyResultMsg struct {
+	err error
+}
+
+// notify posts text to the configured Discord webhook, if any.
+func (m *model) notify(text string) tea.Cmd {
+	if m.webhook == "" {
+		return nil
+	}
+	return postDiscord(m.webhook, text)
+}
+
+func postDiscord(webhook, text string) tea.Cmd {
+	return func() tea.Msg {
+		if r := []rune(text); len(r) > discordMaxLength {
+			text = string(r[:discordMaxLength-1]) + "…"
+		}
+		body, err := json.Marshal(map[string]string{"content": text})
+		if err != nil {
+			return notifyResultMsg{err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+		if err != nil {
+			return notifyResultMsg{err: err}
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return notifyResultMsg{err: err}
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return notifyResultMsg{err: fmt.Errorf("webhook returned %s", resp.Status)}
+		}
+		return notifyResultMsg{}
+	}
+}
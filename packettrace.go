@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gorcon/rcon"
+)
+
+// packet tracing
+
+// packetTap sits between the rcon client and its socket and notes every
+// Source RCON packet that crosses it, for -debug.
+type packetTap struct {
+	net.Conn
+
+	mu      sync.Mutex
+	out, in []byte   // bytes of packets not yet complete
+	packets []string // described packets not yet taken
+}
+
+func (t *packetTap) Write(p []byte) (int, error) {
+	n, err := t.Conn.Write(p)
+	t.mu.Lock()
+	t.out = t.scan(append(t.out, p[:n]...), "→")
+	t.mu.Unlock()
+	return n, err
+}
+
+func (t *packetTap) Read(p []byte) (int, error) {
+	n, err := t.Conn.Read(p)
+	t.mu.Lock()
+	t.in = t.scan(append(t.in, p[:n]...), "←")
+	t.mu.Unlock()
+	return n, err
+}
+
+// scan describes each complete packet at the front of buf and returns what
+// is left over. The caller holds t.mu.
+func (t *packetTap) scan(buf []byte, dir string) []byte {
+	for len(buf) >= 4 {
+		size := int(int32(binary.LittleEndian.Uint32(buf)))
+		if size < 10 || len(buf) < 4+size {
+			if size < 10 {
+				t.packets = append(t.packets, fmt.Sprintf("%s malformed packet, size field %d", dir, size))
+				return nil
+			}
+			return buf
+		}
+		id := int32(binary.LittleEndian.Uint32(buf[4:]))
+		typ := int32(binary.LittleEndian.Uint32(buf[8:]))
+		body := buf[12 : 4+size-2]
+		line := fmt.Sprintf("%s id=%d type=%s size=%d body=%dB", dir, id, packetType(typ, dir), size, len(body))
+		if !utf8.Valid(body) {
+			line += " (not valid UTF-8)"
+		}
+		t.packets = append(t.packets, line)
+		buf = buf[4+size:]
+	}
+	return buf
+}
+
+// packetType names a packet type. Requests and responses reuse the value 2,
+// so it depends on the direction.
+func packetType(typ int32, dir string) string {
+	switch {
+	case typ == rcon.SERVERDATA_AUTH:
+		return "AUTH"
+	case typ == rcon.SERVERDATA_EXECCOMMAND && dir == "→":
+		return "EXECCOMMAND"
+	case typ == rcon.SERVERDATA_AUTH_RESPONSE:
+		return "AUTH_RESPONSE"
+	case typ == rcon.SERVERDATA_RESPONSE_VALUE:
+		return "RESPONSE_VALUE"
+	}
+	return fmt.Sprint(typ)
+}
+
+func (t *packetTap) take() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p := t.packets
+	t.packets = nil
+	return p
+}
+
+// tracedConn is an rcon connection whose packets are being tapped.
+type tracedConn struct {
+	rconClient
+	tap *packetTap
+}
+
+// packetsOf returns the packets traced on client since the last call, or
+// nil if it isn't traced.
+func packetsOf(client rconClient) []string {
+	if tc, ok := client.(*tracedConn); ok {
+		return tc.tap.take()
+	}
+	return nil
+}
+
+// logPackets adds the traced packets of a command to its server's log.
+func (m *model) logPackets(msg rconResultMsg) {
+	for _, p := range msg.packets {
+		m.pushLogFor(msg.serverName, logDebug, fmt.Sprintf("[%s] 📦 %s", msg.serverName, p))
+	}
+}
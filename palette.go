@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quick-command palette
+
+// palette is the open quick-command menu: the active server's
+// quick_commands, one of them selected.
+type palette struct {
+	serverName string
+	cmds       []string
+	cursor     int
+}
+
+// openPalette shows the active server's quick commands, or says why there
+// are none to show.
+func (m *model) openPalette() {
+	s := m.activeServer()
+	if s == nil || len(s.QuickCommands) == 0 {
+		m.setStatus("No quick_commands configured for this server")
+		return
+	}
+	m.palette = &palette{serverName: s.Name, cmds: s.QuickCommands}
+}
+
+// updatePalette handles keys while the palette is open: Up and Down move
+// the selection, Enter sends it as if typed, and Esc, Ctrl+C or the palette
+// key closes it.
+func (m model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.palette
+	switch {
+	case msg.String() == "up", msg.String() == "k", msg.String() == "shift+tab":
+		p.cursor = (p.cursor + len(p.cmds) - 1) % len(p.cmds)
+	case msg.String() == "down", msg.String() == "j", msg.String() == "tab":
+		p.cursor = (p.cursor + 1) % len(p.cmds)
+	case msg.String() == "enter":
+		m.palette = nil
+		return m.submit(p.cmds[p.cursor])
+	case msg.String() == "esc", m.keys.matches(msg, "palette"), m.keys.matches(msg, "quit"):
+		m.palette = nil
+	}
+	return m, nil
+}
+
+// paletteView renders the open palette centred in the window.
+func (m model) paletteView() string {
+	p := m.palette
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render(fmt.Sprintf("Quick commands · %s", p.serverName)))
+	b.WriteString("\n")
+	for i, cmd := range p.cmds {
+		if i == p.cursor {
+			fmt.Fprintf(&b, "\n%s", activeCandidateStyle.Render("> "+cmd))
+		} else {
+			fmt.Fprintf(&b, "\n%s", candidateStyle.Render("  "+cmd))
+		}
+	}
+	b.WriteString("\n\n" + helpDescStyle.Render("Up/Down choose, Enter send, Esc close"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpBoxStyle.Render(b.String()))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// panels
+
+// panel draws body in a rounded border with title set into the top edge.
+// width and height are the content size inside the border. The focused
+// panel's border is drawn in the theme's border color, the others dimmed.
+func panel(title, body string, width, height int, focused bool) string {
+	color := activeTheme.Status
+	if focused {
+		color = activeTheme.Border
+	}
+	b := lipgloss.RoundedBorder()
+	edge := lipgloss.NewStyle().Foreground(color)
+
+	label := lipgloss.NewStyle().Foreground(color).Bold(focused).Render(" " + truncate(title, atLeast(width-3, 1)) + " ")
+	fill := atLeast(width-1-lipgloss.Width(label), 0)
+	top := edge.Render(b.TopLeft+b.Top) + label + edge.Render(strings.Repeat(b.Top, fill)+b.TopRight)
+
+	// Clip rather than let a tall body push the bottom border off the panel.
+	if lines := strings.Split(body, "\n"); len(lines) > height {
+		body = strings.Join(lines[:height], "\n")
+	}
+	box := lipgloss.NewStyle().
+		Border(b, false, true, true, true).
+		BorderForeground(color).
+		Width(width).
+		Height(height).
+		Render(body)
+	return top + "\n" + box
+}
+
+// overlay draws box over view with its top-left corner at column x of row
+// y, keeping what is on either side of it.
+func overlay(view, box string, x, y int) string {
+	lines := strings.Split(view, "\n")
+	for i, row := range strings.Split(box, "\n") {
+		if y+i < 0 || y+i >= len(lines) {
+			continue
+		}
+		line := lines[y+i]
+		pad := strings.Repeat(" ", atLeast(x-ansi.StringWidth(line), 0))
+		lines[y+i] = ansi.Truncate(line, x, "") + pad + row + ansi.TruncateLeft(line, x+ansi.StringWidth(row), "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// password prompt
+
+// errNoPassword means a prompt_password server was dialed before its
+// password was entered.
+var errNoPassword = errors.New("no password entered yet")
+
+// needsPassword reports whether s asks for its password at the prompt and
+// hasn't been given it yet.
+func (s serverConfig) needsPassword() bool {
+	return s.PromptPassword && s.Password == ""
+}
+
+// passwordPrompt is the open masked-entry overlay for one server. pending is
+// the input that asked for it, submitted again once the password is in.
+type passwordPrompt struct {
+	serverName string
+	input      textinput.Model
+	pending    string
+}
+
+// askPassword opens the password overlay for the named server.
+func (m *model) askPassword(name, pending string) {
+	ti := textinput.New()
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	ti.Prompt = "Password: "
+	ti.Focus()
+	m.password = &passwordPrompt{serverName: name, input: ti, pending: pending}
+}
+
+// missingPassword returns the first server the lines would be sent to that
+// still needs its password entered, or "" if none does.
+func (m *model) missingPassword(active serverConfig, lines []string) string {
+	for _, line := range lines {
+		targets := []serverConfig{active}
+		if sel, _, ok := broadcastLine(line); ok {
+			targets, _ = selectServers(m.servers, sel)
+		}
+		for _, t := range targets {
+			if t.needsPassword() {
+				return t.Name
+			}
+		}
+	}
+	return ""
+}
+
+// setPassword keeps an entered password for the rest of the session. It is
+// never written anywhere.
+func (m *model) setPassword(name, pw string) {
+	m.passwords[name] = pw
+	for i := range m.servers {
+		if m.servers[i].Name == name {
+			m.servers[i].Password = pw
+		}
+	}
+}
+
+// forgetPassword drops an entered password the server turned down, so the
+// next command asks again.
+func (m *model) forgetPassword(name string) {
+	if _, ok := m.passwords[name]; !ok {
+		return
+	}
+	delete(m.passwords, name)
+	for i := range m.servers {
+		if m.servers[i].Name == name {
+			m.servers[i].Password = ""
+		}
+	}
+}
+
+// updatePassword handles keys while the password overlay is open: Enter
+// stores the password and sends whatever was waiting on it, Esc or Ctrl+C
+// drops both, and everything else goes to the masked input.
+func (m model) updatePassword(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.password
+	switch {
+	case msg.String() == "enter":
+		pw := p.input.Value()
+		if pw == "" {
+			return m, nil
+		}
+		m.password = nil
+		m.setPassword(p.serverName, pw)
+		m.pushLogFor(p.serverName, logInfo, fmt.Sprintf("[%s] 🔑 Password entered; kept in memory for this session", p.serverName))
+		return m.submit(p.pending)
+	case msg.String() == "esc", msg.Type != tea.KeyRunes && m.keys.matches(msg, "quit"):
+		m.password = nil
+		m.pushLogFor(p.serverName, logWarn, fmt.Sprintf("[%s] 🚫 Cancelled: no password entered", p.serverName))
+		return m, nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// passwordView renders the open overlay centred in the window.
+func (m model) passwordView() string {
+	p := m.password
+	var b strings.Builder
+	b.WriteString(helpTitleStyle.Render(fmt.Sprintf("Password · %s", p.serverName)))
+	b.WriteString("\n\n" + p.input.View())
+	b.WriteString("\n\n" + helpDescStyle.Render("Enter send, Esc cancel. Kept in memory until bubblecon exits."))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpBoxStyle.Render(b.String()))
+}
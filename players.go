@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// player list parsing
+
+// playerListParser extracts player names from the response to a
+// player-listing command. ok is false when output isn't in the expected
+// format, in which case the response is only logged.
+type playerListParser struct {
+	command string // first word of the command that lists players
+	parse   func(output string) (names []string, ok bool)
+}
+
+// playerListParsers is keyed by server type.
+var playerListParsers = map[string]playerListParser{
+	typeMinecraft: {"list", parseMinecraftList},
+	typeFactorio:  {"/players", parseFactorioPlayers},
+	typeSource:    {"status", parseSourceStatus},
+}
+
+// parsePlayerList runs the parser for the server's type if cmd is that
+// type's player-listing command.
+func parsePlayerList(serverType, cmd, output string) ([]string, bool) {
+	p, ok := playerListParsers[serverType]
+	if !ok {
+		return nil, false
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 || !strings.EqualFold(strings.TrimPrefix(fields[0], "/"), strings.TrimPrefix(p.command, "/")) {
+		return nil, false
+	}
+	return p.parse(output)
+}
+
+// "There are 3 of a max of 20 players online: alice, bob, carol"
+// "There are 3/20 players online: alice, bob, carol" (older servers)
+var minecraftListPattern = regexp.MustCompile(`(?s)There are \d+ (?:of a max of |/)\d+ players online:(.*)`)
+
+func parseMinecraftList(output string) ([]string, bool) {
+	m := minecraftListPattern.FindStringSubmatch(output)
+	if m == nil {
+		return nil, false
+	}
+	return splitNames(m[1], ","), true
+}
+
+// "Online players (2):\n  alice (online)\n  bob (online)"
+var factorioPlayersPattern = regexp.MustCompile(`^(?:Online )?[Pp]layers \(\d+\):`)
+
+func parseFactorioPlayers(output string) ([]string, bool) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if !factorioPlayersPattern.MatchString(lines[0]) {
+		return nil, false
+	}
+	var names []string
+	for _, l := range lines[1:] {
+		name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(l), "(online)"))
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, true
+}
+
+// `#      2 "alice"   STEAM_1:0:1234 12:01 48 0 active`
+var sourceStatusRow = regexp.MustCompile(`^#\s*\d+\s+(?:\d+\s+)?"(.*)"`)
+
+func parseSourceStatus(output string) ([]string, bool) {
+	if !strings.Contains(output, "# userid") {
+		return nil, false
+	}
+	var names []string
+	for _, l := range strings.Split(output, "\n") {
+		if m := sourceStatusRow.FindStringSubmatch(strings.TrimSpace(l)); m != nil {
+			names = append(names, m[1])
+		}
+	}
+	return names, true
+}
+
+func splitNames(s, sep string) []string {
+	var names []string
+	for _, n := range strings.Split(s, sep) {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// player panel
+
+const maxPanelRows = 10
+
+// playerPanel renders the last parsed player list of the active server for
+// the bottom of the left column, or "" if there is none.
+func (m model) playerPanel() string {
+	names, ok := m.playerLists[m.activeName]
+	if !ok {
+		return ""
+	}
+
+	rows := []string{panelTitleStyle.Render(fmt.Sprintf("Players (%d)", len(names)))}
+	shown := names
+	if len(shown) > maxPanelRows {
+		shown = shown[:maxPanelRows-1]
+	}
+	for _, n := range shown {
+		rows = append(rows, "  "+truncate(n, listWidth-2))
+	}
+	if len(shown) < len(names) {
+		rows = append(rows, fmt.Sprintf("  +%d more", len(names)-len(shown)))
+	}
+	return lipgloss.NewStyle().Width(listWidth).PaddingTop(1).Render(strings.Join(rows, "\n"))
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
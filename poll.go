@@ -0,0 +1,68 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+// reachability polling
+
+type reachState int
+
+const (
+	reachUnknown reachState = iota
+	reachOnline
+	reachOffline
+	reachBackup // only a backup address answered
+)
+
+type pollTickMsg struct{}
+
+type statusPollMsg struct {
+	reach map[string]reachState // keyed by server name
+}
+
+func schedulePoll(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg { return pollTickMsg{} })
+}
+
+// pollReachability dials every server concurrently and reports which ones
+// accepted an authenticated RCON connection, and whether it took a backup
+// address to get one.
+func pollReachability(servers []serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		reach := make(map[string]reachState, len(servers))
+
+		for _, s := range servers {
+			if s.needsPassword() {
+				continue // unknown until the password is entered
+			}
+			wg.Add(1)
+			go func(s serverConfig) {
+				defer wg.Done()
+				state := reachOffline
+				if conn, addr, err := dial(s); err == nil {
+					conn.Close()
+					state = reachOnline
+					if s.isBackup(addr) {
+						state = reachBackup
+					}
+				}
+				mu.Lock()
+				reach[s.Name] = state
+				mu.Unlock()
+			}(s)
+		}
+		wg.Wait()
+
+		return statusPollMsg{reach: reach}
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorcon/rcon"
+)
+
+const defaultIdleTimeout = 5 * time.Minute
+
+// connection pool
+
+// rconClient is an authenticated connection to one server, over either
+// protocol.
+type rconClient interface {
+	Execute(cmd string) (string, error)
+	Close() error
+}
+
+type pooledConn struct {
+	conn      rconClient
+	timer     *time.Timer
+	idleSince time.Time // when the last real command finished
+}
+
+// connPool keeps one authenticated RCON connection per server name alive
+// between commands. A connection is checked out for the duration of a single
+// command, so two commands to the same server never share a socket.
+type connPool struct {
+	mu          sync.Mutex
+	conns       map[string]*pooledConn
+	inUse       map[string]int    // checked-out connections, keyed by server name
+	closing     map[string]int    // checked-out connections to close when they come back
+	via         map[string]string // address each server was last dialed at
+	idleTimeout time.Duration
+	changed     chan struct{} // signalled when a server may have gained or lost its connection
+}
+
+func newConnPool(idleTimeout time.Duration) *connPool {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+	return &connPool{
+		conns:       make(map[string]*pooledConn),
+		inUse:       make(map[string]int),
+		closing:     make(map[string]int),
+		via:         make(map[string]string),
+		idleTimeout: idleTimeout,
+		changed:     make(chan struct{}, 1),
+	}
+}
+
+// connected reports whether the pool holds an authenticated connection to
+// the named server, idle or checked out.
+func (p *connPool) connected(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, idle := p.conns[name]
+	return idle || p.inUse[name] > 0
+}
+
+// endpoint returns the address name's connection was dialed at, or "" if
+// the pool holds none.
+func (p *connPool) endpoint(name string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, idle := p.conns[name]; !idle && p.inUse[name] == 0 {
+		return ""
+	}
+	return p.via[name]
+}
+
+// signal notes a change for the UI without blocking; changes that arrive
+// before the last one was picked up are coalesced. The caller holds p.mu.
+func (p *connPool) signal() {
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
+
+// get checks out the pooled connection for s, dialing a new one if none is
+// idle. dialed reports whether it had to.
+func (p *connPool) get(s serverConfig) (conn rconClient, dialed bool, err error) {
+	p.mu.Lock()
+	pc, ok := p.conns[s.Name]
+	if ok {
+		delete(p.conns, s.Name)
+		pc.timer.Stop()
+		p.inUse[s.Name]++
+	}
+	p.mu.Unlock()
+
+	if ok {
+		return pc.conn, false, nil
+	}
+	conn, addr, err := dial(s)
+	if err != nil {
+		return nil, true, err
+	}
+	if s.PostAuthDelay > 0 {
+		// Some modded servers drop a command that follows auth too closely.
+		time.Sleep(s.PostAuthDelay)
+	}
+	p.mu.Lock()
+	p.inUse[s.Name]++
+	p.via[s.Name] = addr
+	p.signal()
+	p.mu.Unlock()
+	return conn, true, nil
+}
+
+// dialAddress opens an authenticated connection to s at addr over its
+// configured protocol.
+func dialAddress(s serverConfig, addr string) (rconClient, error) {
+	if s.Protocol == protocolWebSocket {
+		conn, err := dialWebRCON(addr, s.Password, s.dialTimeout(), s.commandTimeout(), s.dialTCP)
+		if err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+	multiPacket := s.profile().multiPacket
+	if s.Proxy != "" || s.tracePackets || multiPacket {
+		nc, err := s.dialTCP("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("rcon: %w", err)
+		}
+		var tap *packetTap
+		if s.tracePackets {
+			tap = &packetTap{Conn: nc}
+			nc = tap
+		}
+		var conn rconClient
+		if multiPacket {
+			sc, err := openSource(nc, s.Password, s.dialTimeout(), s.commandTimeout())
+			if err != nil {
+				return nil, err
+			}
+			conn = sc
+		} else {
+			rc, err := rcon.Open(nc, s.Password, rcon.SetDialTimeout(s.dialTimeout()), rcon.SetDeadline(s.commandTimeout()))
+			if err != nil {
+				return nil, err
+			}
+			conn = rc
+		}
+		if tap != nil {
+			return &tracedConn{rconClient: conn, tap: tap}, nil
+		}
+		return conn, nil
+	}
+	conn, err := rcon.Dial(addr, s.Password, rcon.SetDialTimeout(s.dialTimeout()), rcon.SetDeadline(s.commandTimeout()))
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// put returns a healthy connection to the pool and arms its idle timer.
+func (p *connPool) put(name string, conn rconClient) {
+	p.putIdle(name, conn, time.Now())
+}
+
+// putIdle is put for a connection that has been idle since the given time.
+func (p *connPool) putIdle(name string, conn rconClient, since time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inUse[name]--
+
+	if p.closing[name] > 0 {
+		p.closing[name]--
+		conn.Close()
+		p.signal()
+		return
+	}
+	if _, ok := p.conns[name]; ok {
+		// Another command already returned a connection for this server.
+		conn.Close()
+		return
+	}
+
+	pc := &pooledConn{conn: conn, idleSince: since}
+	pc.timer = time.AfterFunc(p.idleTimeout-time.Since(since), func() { p.expire(name, pc) })
+	p.conns[name] = pc
+}
+
+// ping sends cmd on the idle connection for s, if there is one, so the
+// server doesn't time it out. It never dials, and the idle timer keeps
+// counting from the last real command, so idle_timeout still applies.
+func (p *connPool) ping(s serverConfig, cmd string) (sent bool, err error) {
+	p.mu.Lock()
+	pc, ok := p.conns[s.Name]
+	if ok {
+		delete(p.conns, s.Name)
+		pc.timer.Stop()
+		p.inUse[s.Name]++
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	_, err = execute(context.Background(), pc.conn, cmd, s.commandTimeout())
+	packetsOf(pc.conn) // keep pings out of the next command's trace
+	if err != nil {
+		p.discard(s.Name, pc.conn)
+		return true, err
+	}
+	p.putIdle(s.Name, pc.conn, pc.idleSince)
+	return true, nil
+}
+
+// replace swaps any idle connection for s with a freshly dialed one, for
+// when the old one is known to be stale, e.g. after the server restarted.
+func (p *connPool) replace(s serverConfig) error {
+	p.drop(s.Name)
+	conn, _, err := p.get(s)
+	if err != nil {
+		return err
+	}
+	p.put(s.Name, conn)
+	return nil
+}
+
+// drop closes the idle connection for name, if there is one.
+func (p *connPool) drop(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pc, ok := p.conns[name]; ok {
+		pc.timer.Stop()
+		pc.conn.Close()
+		delete(p.conns, name)
+		p.signal()
+	}
+}
+
+// reset closes every connection to name: the idle one now, and any that are
+// checked out as they come back. It is for when the server's address or
+// credentials changed, so none of them can be reused.
+func (p *connPool) reset(name string) {
+	p.drop(name)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closing[name] = p.inUse[name]
+}
+
+// discard closes a connection that failed mid-command so the next get re-dials.
+func (p *connPool) discard(name string, conn rconClient) {
+	conn.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse[name]--
+	if p.closing[name] > 0 {
+		p.closing[name]--
+	}
+	p.signal()
+}
+
+func (p *connPool) expire(name string, pc *pooledConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conns[name] == pc {
+		delete(p.conns, name)
+		pc.conn.Close()
+		p.signal()
+	}
+}
+
+func (p *connPool) closeAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, pc := range p.conns {
+		pc.timer.Stop()
+		pc.conn.Close()
+		delete(p.conns, name)
+	}
+}
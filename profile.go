@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// profiles
+
+var profileExts = []string{".yaml", ".yml", ".toml"}
+
+// profilePath returns the config file of the named profile in configDir:
+// name.yaml, or name.yml or name.toml if that is what exists. A profile
+// with no file yet gets the .yaml path.
+func profilePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	for _, ext := range profileExts {
+		p := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+// listProfiles prints the profiles named in the profiles setting of the
+// given config files, then any other profile files found in configDir. The
+// default config.yaml there is loaded without -profile, so it isn't listed.
+func listProfiles(paths []string) error {
+	var names []string
+	for _, path := range paths {
+		listed, err := configuredProfiles(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, n := range listed {
+			if !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		n := strings.TrimSuffix(e.Name(), ext)
+		if !e.IsDir() && n != "config" && slices.Contains(profileExts, ext) && !slices.Contains(names, n) {
+			names = append(names, n)
+		}
+	}
+
+	if len(names) == 0 {
+		fmt.Printf("No profiles yet. Put one config per profile in %s, e.g. %s\n", dir, filepath.Join(dir, "home.yaml"))
+		return nil
+	}
+	width := 0
+	for _, n := range names {
+		width = max(width, len(n))
+	}
+	for _, n := range names {
+		p, err := profilePath(n)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(p); err != nil {
+			p += " (missing)"
+		}
+		fmt.Printf("%-*s  %s\n", width, n, p)
+	}
+	return nil
+}
+
+// configuredProfiles reads just the profiles setting of a config file.
+func configuredProfiles(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var top struct {
+		Profiles []string `yaml:"profiles" toml:"profiles"`
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".toml" {
+		_, err = toml.Decode(string(data), &top)
+	} else {
+		err = yaml.Unmarshal(data, &top)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return top.Profiles, nil
+}
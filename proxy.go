@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/proxy"
+)
+
+// proxies
+
+// parseProxy checks a proxy setting: a socks5:// (or socks5h://) URL, with
+// optional user:password credentials.
+func parseProxy(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "socks5", "socks5h":
+	case "ssh":
+		return nil, fmt.Errorf("ssh proxies aren't supported; open a SOCKS tunnel with `ssh -N -D 1080 %s` and use socks5://127.0.0.1:1080", u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected socks5", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q has no host", raw)
+	}
+	return u, nil
+}
+
+// dialTCP opens a TCP connection to addr for s, through its proxy if it has
+// one, giving up after the server's dial timeout either way.
+func (s serverConfig) dialTCP(network, addr string) (net.Conn, error) {
+	direct := &net.Dialer{Timeout: s.dialTimeout()}
+	if s.Proxy == "" {
+		return direct.Dial(network, addr)
+	}
+	u, err := parseProxy(s.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	d, err := proxy.FromURL(u, direct)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	if cd, ok := d.(proxy.ContextDialer); ok {
+		// The timeout covers the proxy handshake too, not just reaching it.
+		ctx, cancel := context.WithTimeout(context.Background(), s.dialTimeout())
+		defer cancel()
+		conn, err = cd.DialContext(ctx, network, addr)
+	} else {
+		conn, err = d.Dial(network, addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("via proxy %s: %w", u.Host, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// player count polling
+
+type playerCount struct {
+	online  int
+	max     int
+	title   string // server name as reported by the query, if any
+	mapName string // current map, for Source servers
+}
+
+type playerPollMsg struct {
+	counts map[string]playerCount // keyed by server name; missing means unknown
+}
+
+// pollPlayers queries every server with a query address, using the protocol
+// of its type, concurrently.
+func pollPlayers(servers []serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		counts := make(map[string]playerCount)
+
+		for _, s := range servers {
+			query, addr := s.profile().query, s.queryAddress()
+			if query == nil || addr == "" {
+				continue
+			}
+			wg.Add(1)
+			go func(s serverConfig) {
+				defer wg.Done()
+				pc, err := query(addr, s.dialTimeout())
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				counts[s.Name] = pc
+				mu.Unlock()
+			}(s)
+		}
+		wg.Wait()
+
+		return playerPollMsg{counts: counts}
+	}
+}
+
+// minecraftPing performs a Minecraft Server List Ping and returns the
+// reported player counts.
+func minecraftPing(address string, timeout time.Duration) (playerCount, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return playerCount{}, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return playerCount{}, fmt.Errorf("invalid port %q", portStr)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return playerCount{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	// Handshake (next state 1 = status), then an empty status request.
+	var hs bytes.Buffer
+	writeVarInt(&hs, 0x00)
+	writeVarInt(&hs, -1) // protocol version: unknown
+	writeVarInt(&hs, int32(len(host)))
+	hs.WriteString(host)
+	binary.Write(&hs, binary.BigEndian, uint16(port))
+	writeVarInt(&hs, 1)
+
+	var out bytes.Buffer
+	writeVarInt(&out, int32(hs.Len()))
+	out.Write(hs.Bytes())
+	writeVarInt(&out, 1)
+	writeVarInt(&out, 0x00)
+	if _, err := conn.Write(out.Bytes()); err != nil {
+		return playerCount{}, err
+	}
+
+	r := bufio.NewReader(conn)
+	if _, err := readVarInt(r); err != nil { // packet length
+		return playerCount{}, err
+	}
+	if id, err := readVarInt(r); err != nil {
+		return playerCount{}, err
+	} else if id != 0x00 {
+		return playerCount{}, fmt.Errorf("unexpected packet id %d", id)
+	}
+	n, err := readVarInt(r)
+	if err != nil {
+		return playerCount{}, err
+	}
+	if n < 0 || n > 1<<20 {
+		return playerCount{}, fmt.Errorf("invalid status length %d", n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return playerCount{}, err
+	}
+
+	var status struct {
+		Players struct {
+			Online int `json:"online"`
+			Max    int `json:"max"`
+		} `json:"players"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return playerCount{}, fmt.Errorf("invalid status response: %w", err)
+	}
+	return playerCount{online: status.Players.Online, max: status.Players.Max}, nil
+}
+
+func writeVarInt(w *bytes.Buffer, v int32) {
+	u := uint32(v)
+	for {
+		if u&^0x7F == 0 {
+			w.WriteByte(byte(u))
+			return
+		}
+		w.WriteByte(byte(u&0x7F | 0x80))
+		u >>= 7
+	}
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var result uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		result |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(result), nil
+		}
+	}
+	return 0, errors.New("varint too long")
+}
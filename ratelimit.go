@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rate limiting
+
+// sendInterval is the minimum gap between commands to s, or 0 when s has no
+// rate_limit.
+func (s serverConfig) sendInterval() time.Duration {
+	if s.RateLimit <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / s.RateLimit)
+}
+
+// queueSend books cmd the next free send slot on s and holds it back until
+// then. Slots are handed out in the order commands are dispatched, so a
+// server's queue drains in order whichever server is active meanwhile.
+func (m *model) queueSend(s serverConfig, cmd tea.Cmd) tea.Cmd {
+	interval := s.sendInterval()
+	if interval == 0 {
+		return cmd
+	}
+	now := time.Now()
+	at := m.nextSend[s.Name]
+	if at.Before(now) {
+		at = now
+	}
+	m.nextSend[s.Name] = at.Add(interval)
+	if !at.After(now) {
+		return cmd
+	}
+	ctx := m.sendCtx
+	return func() tea.Msg {
+		select {
+		case <-time.After(time.Until(at)):
+		case <-ctx.Done():
+		}
+		return cmd()
+	}
+}
+
+// queued returns how many commands to the named server are still waiting
+// for their slot.
+func (m *model) queued(name string) int {
+	s := m.serverByName(name)
+	if s == nil || s.sendInterval() == 0 {
+		return 0
+	}
+	// Booked slots run up to one interval before nextSend.
+	ahead := time.Until(m.nextSend[name]) - s.sendInterval()
+	if ahead <= 0 {
+		return 0
+	}
+	return int((ahead + s.sendInterval() - 1) / s.sendInterval())
+}
+
+// concurrency limit
+
+const defaultMaxConcurrency = 10
+
+// limited runs send once one of the max_concurrency slots is free, so a
+// broadcast to many servers doesn't open every connection at once.
+// Cancelling ctx stops the wait, and send then reports the cancellation.
+func limited(slots chan struct{}, ctx context.Context, send tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+		}
+		return send()
+	}
+}
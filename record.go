@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// session recording
+
+// recordEvent is one line of a -record file: a command the user entered,
+// or the result of an RCON command or docker action.
+type recordEvent struct {
+	At       int64  `json:"at_ms"` // since the recording started
+	Type     string `json:"type"`  // "command", "rcon" or "docker"
+	Server   string `json:"server"`
+	Cmd      string `json:"cmd,omitempty"`
+	Expanded string `json:"expanded,omitempty"` // the alias expansion of cmd, if any
+	Action   string `json:"action,omitempty"`
+	Output   string `json:"output,omitempty"`
+	Error    string `json:"error,omitempty"`
+	RTT      int64  `json:"rtt_ms,omitempty"`
+}
+
+// recorder appends events to a JSONL file as they happen, so a recording
+// survives a crash up to its last event.
+type recorder struct {
+	f     *os.File
+	start time.Time
+}
+
+func newRecorder(path string) (*recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	return &recorder{f: f, start: time.Now()}, nil
+}
+
+func (r *recorder) write(ev recordEvent) error {
+	ev.At = time.Since(r.start).Milliseconds()
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = r.f.Write(append(b, '\n'))
+	return err
+}
+
+func (r *recorder) close() error {
+	return r.f.Close()
+}
+
+// record writes ev to the -record file, if there is one. A failed write
+// stops the recording rather than the session.
+func (m *model) record(ev recordEvent) {
+	if m.recorder == nil {
+		return
+	}
+	if err := m.recorder.write(ev); err != nil {
+		m.recorder.close()
+		m.recorder = nil
+		m.pushLog(logError, fmt.Sprintf("❌ Recording stopped: %v", err))
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func rconEvent(msg rconResultMsg) recordEvent {
+	return recordEvent{
+		Type:   "rcon",
+		Server: msg.serverName,
+		Cmd:    msg.cmd,
+		Output: msg.output,
+		Error:  errorText(msg.err),
+		RTT:    msg.rtt.Milliseconds(),
+	}
+}
+
+func dockerEvent(msg dockerResultMsg) recordEvent {
+	return recordEvent{
+		Type:   "docker",
+		Server: msg.serverName,
+		Action: msg.action,
+		Output: msg.output,
+		Error:  errorText(msg.err),
+	}
+}
+
+// replay
+
+// replayCommandMsg shows a recorded user command in the log.
+type replayCommandMsg struct {
+	serverName string
+	cmd        string
+	expanded   string
+}
+
+// replayMsg delivers the recording's event at index.
+type replayMsg struct {
+	index int
+}
+
+// loadRecording reads a -record file back.
+func loadRecording(path string) ([]recordEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	defer f.Close()
+
+	var events []recordEvent
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 16<<20) // responses can be long
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var ev recordEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		events = append(events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("recording %s has no events", path)
+	}
+	return events, nil
+}
+
+// msg rebuilds the message the event was recorded from.
+func (ev recordEvent) msg() tea.Msg {
+	var err error
+	if ev.Error != "" {
+		err = errors.New(ev.Error)
+	}
+	switch ev.Type {
+	case "rcon":
+		return rconResultMsg{
+			serverName: ev.Server,
+			cmd:        ev.Cmd,
+			output:     ev.Output,
+			err:        err,
+			rtt:        time.Duration(ev.RTT) * time.Millisecond,
+			attempt:    1,
+		}
+	case "docker":
+		return dockerResultMsg{serverName: ev.Server, action: ev.Action, output: ev.Output, err: err}
+	}
+	return replayCommandMsg{serverName: ev.Server, cmd: ev.Cmd, expanded: ev.Expanded}
+}
+
+// startReplay begins feeding the -replay recording back.
+func (m model) startReplay() tea.Cmd {
+	if m.replay == nil {
+		return nil
+	}
+	return m.replayStep(0)
+}
+
+// replayStep schedules the event at index after the gap that separated it
+// from the previous one when it was recorded, or right away with
+// -replay-instant. Past the last event it reports the replay finished.
+func (m model) replayStep(index int) tea.Cmd {
+	var delay time.Duration
+	if index < len(m.replay) && !m.replayInstant {
+		delay = time.Duration(m.replay[index].At) * time.Millisecond
+		if index > 0 {
+			delay -= time.Duration(m.replay[index-1].At) * time.Millisecond
+		}
+	}
+	msg := replayMsg{index: index}
+	if delay <= 0 {
+		return func() tea.Msg { return msg }
+	}
+	return tea.Tick(delay, func(time.Time) tea.Msg { return msg })
+}
+
+// replayEvent delivers one recorded event and queues the next.
+func (m *model) replayEvent(index int) tea.Cmd {
+	if index >= len(m.replay) {
+		m.pushLog(logInfo, "⏹️ Replay finished")
+		m.setStatus("Replay finished")
+		return nil
+	}
+	if index == 0 {
+		m.pushLog(logInfo, fmt.Sprintf("▶️ Replaying %d recorded events", len(m.replay)))
+	}
+	ev := m.replay[index].msg()
+	return tea.Sequence(func() tea.Msg { return ev }, m.replayStep(index+1))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// config reload
+
+// configReloadMsg carries the config files as they read now.
+type configReloadMsg struct {
+	cfg      appConfig
+	warnings []string
+	err      error
+}
+
+// reloadConfig loads the config files again off the UI goroutine, since
+// passwords may come from exec: commands.
+func reloadConfig(paths configPaths) tea.Cmd {
+	return func() tea.Msg {
+		cfg, warnings, err := loadConfigs(paths)
+		return configReloadMsg{cfg: cfg, warnings: warnings, err: err}
+	}
+}
+
+// applyGlobals swaps in the reloaded top-level settings that shape the UI:
+// aliases, templates, highlights, watches, keybindings and the theme, unless
+// -theme picked one. It returns warnings about settings it couldn't apply.
+func (m *model) applyGlobals(cfg appConfig) []string {
+	m.aliases, m.templates = cfg.Aliases, cfg.Templates
+	m.highlights, m.watches = cfg.highlights, cfg.watches
+
+	keys, warnings := newKeyMap(cfg.Keybindings, cfg.SendKey)
+	if m.noDocker {
+		keys.disableGroup("Docker")
+	}
+	m.keys, m.placeholder = keys, inputPlaceholder(keys)
+	m.applyPrompt()
+
+	if m.themeFlag == "" {
+		t, err := cfg.Theme.resolve()
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%v; keeping the current theme", err))
+		} else {
+			applyTheme(t)
+			m.spinner.Style = lipgloss.NewStyle().Foreground(activeTheme.Accent)
+		}
+	}
+	m.refreshLog()
+	return warnings
+}
+
+// sameConnection reports whether a connection opened for s can keep
+// serving o.
+func (s serverConfig) sameConnection(o serverConfig) bool {
+	return slices.Equal(s.Address, o.Address) && s.Password == o.Password && s.Protocol == o.Protocol &&
+		s.Type == o.Type && s.Proxy == o.Proxy
+}
+
+// applyServers swaps in a reloaded server list. Pooled connections are kept
+// for servers whose address, credentials or protocol didn't change; those
+// of changed and removed servers are closed. Schedules and keepalives are
+// started for whatever the reload added. It returns the commands to start
+// them and a summary of the changes for the log.
+func (m *model) applyServers(servers []serverConfig) (tea.Cmd, string) {
+	old := make(map[string]serverConfig, len(m.servers))
+	for _, s := range m.servers {
+		old[s.Name] = s
+	}
+
+	var (
+		added, changed, removed []string
+		cmds                    []tea.Cmd
+	)
+	for i := range servers {
+		s := &servers[i]
+		s.tracePackets = m.tracePackets
+		if s.needsPassword() {
+			s.Password = m.passwords[s.Name]
+		}
+		prev, ok := old[s.Name]
+		delete(old, s.Name)
+		switch {
+		case !ok:
+			added = append(added, s.Name)
+			cmds = append(cmds, startSchedules([]serverConfig{*s}), startKeepalives([]serverConfig{*s}))
+			continue
+		case reflect.DeepEqual(prev, *s):
+			continue
+		}
+
+		label := s.Name
+		if !prev.sameConnection(*s) {
+			m.pool.reset(s.Name)
+			label += " (reconnecting)"
+		}
+		changed = append(changed, label)
+		// Running schedules and keepalives look their server up on every
+		// tick, so only ones that weren't running before need starting.
+		for i, e := range s.Schedule {
+			running := i < len(prev.Schedule) && prev.Schedule[i].Interval > 0 && prev.Schedule[i].Command != ""
+			if !running && e.Interval > 0 && e.Command != "" {
+				cmds = append(cmds, scheduleTick(s.Name, i, e.Interval))
+			}
+		}
+		if prev.Keepalive <= 0 && s.Keepalive > 0 {
+			cmds = append(cmds, keepaliveTick(*s))
+		}
+	}
+	for _, s := range m.servers {
+		if _, gone := old[s.Name]; gone {
+			removed = append(removed, s.Name)
+			m.pool.reset(s.Name)
+			m.stopLogStream(s.Name)
+		}
+	}
+
+	m.servers = servers
+	if m.serverByName(m.activeName) == nil {
+		m.setActive(servers[0].Name)
+	}
+	m.applyPrompt()
+	m.rebuildList()
+
+	var parts []string
+	for _, c := range []struct {
+		what  string
+		names []string
+	}{{"added", added}, {"changed", changed}, {"removed", removed}} {
+		if len(c.names) > 0 {
+			parts = append(parts, c.what+" "+strings.Join(c.names, ", "))
+		}
+	}
+	if len(parts) == 0 {
+		return tea.Batch(cmds...), "no server changes"
+	}
+	return tea.Batch(cmds...), strings.Join(parts, "; ")
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scheduled commands
+
+type scheduleEntry struct {
+	Command  string        `yaml:"command" toml:"command"`
+	Interval time.Duration `yaml:"interval" toml:"interval"`
+}
+
+type scheduleTickMsg struct {
+	serverName string
+	index      int // position in the server's Schedule
+}
+
+func scheduleTick(serverName string, index int, every time.Duration) tea.Cmd {
+	return tea.Tick(every, func(time.Time) tea.Msg {
+		return scheduleTickMsg{serverName: serverName, index: index}
+	})
+}
+
+// startSchedules arms the first tick of every scheduled command. Entries
+// without a positive interval are ignored.
+func startSchedules(servers []serverConfig) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, s := range servers {
+		for i, e := range s.Schedule {
+			if e.Interval > 0 && e.Command != "" {
+				cmds = append(cmds, scheduleTick(s.Name, i, e.Interval))
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
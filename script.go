@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// command scripts
+
+// scriptBatch runs a file of commands against one server, one at a time:
+// the next command is only sent once the previous response has arrived.
+type scriptBatch struct {
+	serverName      string
+	cmds            []string
+	next            int // index of the next command to send
+	continueOnError bool
+	failed          int
+
+	line     string   // the line being sent
+	expanded []string // the commands line expands to, more than one for a macro alias
+	isAlias  bool
+	step     int // index into expanded of the next command to send
+}
+
+// runScriptMsg starts a script on the active server; main queues one for
+// the -script flag.
+type runScriptMsg struct {
+	path            string
+	cmds            []string
+	continueOnError bool
+}
+
+// loadScript reads a script file, in the same format as commands_file.
+func loadScript(path string) ([]string, error) {
+	cmds, err := loadCommands(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	if len(cmds) == 0 {
+		return nil, fmt.Errorf("script %s has no commands", path)
+	}
+	return cmds, nil
+}
+
+// runInitScript delivers the -script given on the command line, if any.
+func (m model) runInitScript() tea.Cmd {
+	if m.initScript == nil {
+		return nil
+	}
+	msg := *m.initScript
+	return func() tea.Msg { return msg }
+}
+
+func (m *model) startScript(msg runScriptMsg) tea.Cmd {
+	s := m.activeServer()
+	if s == nil {
+		m.pushLog(logError, "❌ No active server selected.")
+		return nil
+	}
+	if m.batch != nil {
+		m.pushLog(logError, fmt.Sprintf("❌ A script is already running on %s", m.batch.serverName))
+		return nil
+	}
+	m.batch = &scriptBatch{serverName: s.Name, cmds: msg.cmds, continueOnError: msg.continueOnError}
+	m.pushLog(logInfo, fmt.Sprintf("[%s] 📋 Running script %s (%d commands)", s.Name, msg.path, len(msg.cmds)))
+	return m.advanceScript()
+}
+
+// advanceScript sends the running script's next command, or reports the
+// script finished once none are left.
+func (m *model) advanceScript() tea.Cmd {
+	b := m.batch
+	s := m.serverByName(b.serverName)
+	if s == nil || (b.next >= len(b.cmds) && b.step >= len(b.expanded)) {
+		m.pushLogFor(b.serverName, logInfo, fmt.Sprintf("[%s] 📋 Script finished: %d commands, %d failed", b.serverName, len(b.cmds), b.failed))
+		m.setStatus("Script finished")
+		m.batch = nil
+		return nil
+	}
+
+	// A line naming a multi-command alias is sent one command at a time,
+	// and counts as a single line for progress.
+	if b.step >= len(b.expanded) {
+		b.line = b.cmds[b.next]
+		b.next++
+		b.expanded, b.isAlias = expandAlias(b.line, s.Aliases, m.aliases)
+		b.step = 0
+	}
+	cmdStr := b.expanded[b.step]
+	progress := fmt.Sprintf("Running %d/%d", b.next, len(b.cmds))
+	if b.isAlias {
+		m.pushLogFor(s.Name, logCommand, fmt.Sprintf("[%s] 📋 %s > %s", s.Name, progress, aliasStep(b.line, b.expanded, b.step)))
+	} else {
+		m.pushLogFor(s.Name, logCommand, fmt.Sprintf("[%s] 📋 %s > %s", s.Name, progress, cmdStr))
+	}
+	b.step++
+	m.setStatus(progress)
+
+	// Scripts go through blocked_commands like typed input: a blocking rule
+	// stops the script, any other rule asks first.
+	if rule, ok := s.blockedBy(cmdStr); ok {
+		if s.Block {
+			m.pushLogFor(s.Name, logError, fmt.Sprintf("[%s] 🚫 Script stopped at %d/%d: %q matches blocked command %q", s.Name, b.next, len(b.cmds), cmdStr, rule))
+			m.setStatus("Script stopped")
+			m.batch = nil
+			return nil
+		}
+		srv := *s
+		m.confirm(fmt.Sprintf("[%s] Really send %s?", s.Name, cmdStr), func(m *model) tea.Cmd {
+			if m.batch != b {
+				return nil // cancelled while the prompt was open
+			}
+			m.setStatus(progress)
+			return m.sendScriptStep(srv, cmdStr)
+		}, func(m *model) {
+			if m.batch == b {
+				m.pushLogFor(b.serverName, logWarn, fmt.Sprintf("[%s] 📋 Script cancelled at %d/%d", b.serverName, b.next, len(b.cmds)))
+				m.setStatus("Script cancelled")
+				m.batch = nil
+			}
+		})
+		return nil
+	}
+	return m.sendScriptStep(*s, cmdStr)
+}
+
+// sendScriptStep sends one command of the running script to s.
+func (m *model) sendScriptStep(s serverConfig, cmdStr string) tea.Cmd {
+	b, ctx, pool := m.batch, m.sendCtx, m.pool
+	return tea.Batch(m.sending(1), m.queueSend(s, retrying(ctx, s, func(attempt int) tea.Cmd {
+		return withBatch(b, sendRCONAttempt(ctx, pool, s, cmdStr, attempt))
+	})))
+}
+
+// scriptResult moves the script along after one of its commands finished,
+// stopping it on failure unless -continue-on-error was given.
+func (m *model) scriptResult(msg rconResultMsg) tea.Cmd {
+	b := m.batch
+	if msg.err != nil {
+		b.failed++
+		if !b.continueOnError {
+			m.pushLogFor(b.serverName, logError, fmt.Sprintf("[%s] 📋 Script aborted at %d/%d", b.serverName, b.next, len(b.cmds)))
+			m.setStatus("Script aborted")
+			m.batch = nil
+			return nil
+		}
+	}
+	return m.advanceScript()
+}
+
+// withBatch tags the result of send as belonging to b.
+func withBatch(b *scriptBatch, send tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		res := send().(rconResultMsg)
+		res.batch = b
+		return res
+	}
+}
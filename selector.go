@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// server selectors
+
+// selectServers resolves a selector to the servers it names: "@tag:name"
+// picks every server tagged name, and anything else is a glob matched
+// against server names ("survival-*", "*"), with an optional leading "@".
+// A server whose name is exactly sel is the only match.
+func selectServers(servers []serverConfig, sel string) ([]serverConfig, error) {
+	for _, s := range servers {
+		if s.Name == sel {
+			return []serverConfig{s}, nil
+		}
+	}
+
+	var matched []serverConfig
+	if tag, ok := strings.CutPrefix(sel, "@tag:"); ok {
+		for _, s := range servers {
+			if slices.Contains(s.Tags, tag) {
+				matched = append(matched, s)
+			}
+		}
+	} else {
+		pattern := strings.TrimPrefix(sel, "@")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad server pattern %q: %w", sel, err)
+		}
+		for _, s := range servers {
+			if ok, _ := path.Match(pattern, s.Name); ok {
+				matched = append(matched, s)
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no servers match %q", sel)
+	}
+	return matched, nil
+}
+
+// broadcastLine splits an input line addressed to other servers, such as
+// "@tag:survival say Restarting soon" or "@lobby-* say hi", into its
+// selector and command.
+func broadcastLine(line string) (sel, cmd string, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "@") {
+		return "", "", false
+	}
+	sel, cmd, _ = strings.Cut(line, " ")
+	return sel, strings.TrimSpace(cmd), true
+}
+
+func serverNames(servers []serverConfig) string {
+	names := make([]string, len(servers))
+	for i, s := range servers {
+		names[i] = s.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// broadcastTally counts the results of one broadcast line as they come in,
+// once per server however many commands an alias sent it.
+type broadcastTally struct {
+	origin    string // server whose log the broadcast was started from
+	cmd       string
+	pending   int              // servers still waiting on a result
+	total     int              // servers the broadcast went to
+	left      map[string]int   // results each pending server still owes
+	errs      map[string]error // first error each server returned
+	ok        int
+	failed    []string
+	cancelled int
+}
+
+// withBroadcast tags the result of send as counting toward broadcast id.
+func withBroadcast(id int, send tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		res := send().(rconResultMsg)
+		res.broadcast = id
+		return res
+	}
+}
+
+// countBroadcast adds a final result to its broadcast's tally. A server
+// counts once all of its commands are in, as failed if any of them failed.
+// Once every server has answered, it logs a summary where the broadcast
+// started. Each broadcast has its own id, so overlapping ones are counted
+// apart.
+func (m *model) countBroadcast(msg rconResultMsg) {
+	t := m.broadcasts[msg.broadcast]
+	if t == nil {
+		return
+	}
+	if msg.err != nil && t.errs[msg.serverName] == nil {
+		t.errs[msg.serverName] = msg.err
+	}
+	if t.left[msg.serverName]--; t.left[msg.serverName] > 0 {
+		return
+	}
+	err := t.errs[msg.serverName]
+	switch {
+	case errors.Is(err, context.Canceled):
+		t.cancelled++
+	case err != nil:
+		t.failed = append(t.failed, msg.serverName)
+	default:
+		t.ok++
+	}
+	if t.pending--; t.pending > 0 {
+		m.setStatus(fmt.Sprintf("Broadcast: %d/%d done", t.total-t.pending, t.total))
+		return
+	}
+	delete(m.broadcasts, msg.broadcast)
+
+	summary := fmt.Sprintf("%d OK", t.ok)
+	if len(t.failed) > 0 {
+		summary += fmt.Sprintf(", %d failed (%s)", len(t.failed), strings.Join(t.failed, ", "))
+	}
+	if t.cancelled > 0 {
+		summary += fmt.Sprintf(", %d cancelled", t.cancelled)
+	}
+	kind := logInfo
+	if len(t.failed) > 0 {
+		kind = logWarn
+	}
+	m.pushLogFor(t.origin, kind, fmt.Sprintf("📢 Broadcast complete (%s): %s", t.cmd, summary))
+}
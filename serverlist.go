@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// list item
+
+type serverItem struct {
+	serverConfig
+	reach     reachState
+	connected bool            // the pool holds an RCON session to the server
+	players   *playerCount    // nil when unknown or not queried
+	container *containerState // nil when unknown
+}
+
+// sessionGlyph shows whether the next command reuses a pooled session (⇄),
+// has to dial first (·), or has nothing to reach (×).
+func (s serverItem) sessionGlyph() string {
+	switch {
+	case s.connected:
+		return connectedGlyph
+	case s.reach == reachOffline:
+		return noRouteGlyph
+	}
+	return idleGlyph
+}
+
+func (s serverItem) Title() string {
+	dot := unknownDot
+	switch s.reach {
+	case reachOnline:
+		dot = onlineDot
+	case reachOffline:
+		dot = offlineDot
+	case reachBackup:
+		dot = backupDot
+	}
+	title := dot + s.sessionGlyph() + " " + s.Name
+	if s.players != nil {
+		title += fmt.Sprintf(" (%d/%d)", s.players.online, s.players.max)
+	}
+	return title
+}
+func (s serverItem) Description() string {
+	if s.container == nil {
+		return s.Address.summary()
+	}
+	return s.container.style().Render("■") + " " + s.Address.summary()
+}
+func (s serverItem) FilterValue() string { return s.Name + " " + strings.Join(s.Address, " ") }
+
+// groupHeader is a non-selectable section title in the server list.
+type groupHeader struct {
+	name      string
+	count     int
+	collapsed bool
+}
+
+func (g groupHeader) Title() string {
+	arrow := "▾"
+	if g.collapsed {
+		arrow = "▸"
+	}
+	return fmt.Sprintf("%s %s (%d)", arrow, g.name, g.count)
+}
+func (g groupHeader) Description() string { return "" }
+func (g groupHeader) FilterValue() string { return "" }
+
+// serverDelegate renders group headers as plain section titles and
+// everything else with the default delegate.
+type serverDelegate struct {
+	list.DefaultDelegate
+}
+
+func (d serverDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if g, ok := item.(groupHeader); ok {
+		fmt.Fprint(w, groupHeaderStyle.Render(g.Title()))
+		return
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+func (d serverDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
+	return d.DefaultDelegate.Update(msg, m)
+}
+
+// buildItems lays out the server list: ungrouped servers first, then each
+// group in order of first appearance under its header. Servers in collapsed
+// groups are omitted.
+func (m *model) buildItems() []list.Item {
+	var (
+		items  []list.Item
+		groups []string
+	)
+	members := make(map[string][]serverConfig)
+	for _, s := range m.servers {
+		if s.Group == "" {
+			items = append(items, m.serverItem(s))
+			continue
+		}
+		if _, seen := members[s.Group]; !seen {
+			groups = append(groups, s.Group)
+		}
+		members[s.Group] = append(members[s.Group], s)
+	}
+
+	for _, g := range groups {
+		collapsed := m.collapsed[g]
+		items = append(items, groupHeader{name: g, count: len(members[g]), collapsed: collapsed})
+		if collapsed {
+			continue
+		}
+		for _, s := range members[g] {
+			items = append(items, m.serverItem(s))
+		}
+	}
+	return items
+}
+
+// toggleGroup collapses or expands a group, keeping its header selected.
+// It does nothing while the filter prompt is open, since swapping the items
+// then would empty the filtered view.
+func (m *model) toggleGroup(name string) {
+	if m.list.SettingFilter() {
+		return
+	}
+	m.collapsed[name] = !m.collapsed[name]
+	m.list.SetItems(m.buildItems())
+	for i, it := range m.list.Items() {
+		if g, ok := it.(groupHeader); ok && g.name == name {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+func (m *model) serverItem(s serverConfig) serverItem {
+	item := serverItem{serverConfig: s, reach: m.reach[s.Name], players: m.players[s.Name]}
+	if m.pool != nil {
+		item.connected = m.pool.connected(s.Name)
+	}
+	if cs, ok := m.containers[s.Name]; ok {
+		item.container = &cs
+	}
+	return item
+}
+
+// rebuildList refreshes the list items from model state and keeps the
+// active server (or its collapsed group's header) selected. While the list
+// has focus the cursor is the user's, so it stays on the item it was on
+// instead. It is skipped
+// while a filter is open or applied, where swapping the items would empty
+// the filtered view and indexes don't line up; closing the filter rebuilds
+// the list.
+func (m *model) rebuildList() {
+	if m.list.FilterState() != list.Unfiltered {
+		return
+	}
+	if m.focus == focusList {
+		sel := m.list.SelectedItem()
+		m.list.SetItems(m.buildItems())
+		for i, it := range m.list.Items() {
+			if sameItem(it, sel) {
+				m.list.Select(i)
+				return
+			}
+		}
+		return
+	}
+	m.list.SetItems(m.buildItems())
+	active := m.activeServer()
+	for i, it := range m.list.Items() {
+		switch it := it.(type) {
+		case serverItem:
+			if it.Name == m.activeName {
+				m.list.Select(i)
+				return
+			}
+		case groupHeader:
+			if active != nil && it.collapsed && it.name == active.Group {
+				m.list.Select(i)
+			}
+		}
+	}
+}
+
+// sameItem reports whether a and b are the same server or group header,
+// whatever their state.
+func sameItem(a, b list.Item) bool {
+	switch a := a.(type) {
+	case serverItem:
+		b, ok := b.(serverItem)
+		return ok && a.Name == b.Name
+	case groupHeader:
+		b, ok := b.(groupHeader)
+		return ok && a.name == b.name
+	}
+	return false
+}
+
+// listIndexAt maps a terminal row inside the list pane to an item index,
+// following the bubbles list layout: a title and a status bar (each with one
+// line of padding), then one slot of Height()+Spacing() rows per item.
+func (m *model) listIndexAt(y int) (int, bool) {
+	top := 0
+	if m.list.ShowTitle() || (m.list.ShowFilter() && m.list.FilteringEnabled()) {
+		top += 2 // the title bar, which also holds the filter prompt
+	}
+	if m.list.ShowStatusBar() {
+		top += 2
+	}
+	if y < top {
+		return 0, false
+	}
+
+	d := m.delegate
+	slot := d.Height() + d.Spacing()
+	if slot <= 0 {
+		return 0, false
+	}
+	row := y - top
+	if row%slot >= d.Height() {
+		return 0, false // the gap between items
+	}
+
+	idx := m.list.Paginator.Page*m.list.Paginator.PerPage + row/slot
+	if idx >= len(m.list.VisibleItems()) {
+		return 0, false
+	}
+	return idx, true
+}
+
+// nextServerIndex returns the index of the next server item after the
+// current selection, wrapping around and skipping group headers.
+func (m *model) nextServerIndex() (int, bool) {
+	items := m.list.Items()
+	for step := 1; step <= len(items); step++ {
+		i := (m.list.Index() + step) % len(items)
+		if _, ok := items[i].(serverItem); ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gorcon/rcon"
+)
+
+// multi-packet Source RCON
+
+// maxPacketSize bounds the size field of an incoming packet. Servers split
+// responses at 4096 bytes of body; anything far past that is garbage.
+const maxPacketSize = 1 << 16
+
+// sourceConn speaks Source RCON directly instead of through gorcon, whose
+// Execute returns only the first packet of a response. After each command it
+// sends an empty RESPONSE_VALUE packet, which the server mirrors back once
+// every packet of the command's response has gone out; everything before
+// that mirror belongs to the response.
+type sourceConn struct {
+	conn    net.Conn
+	timeout time.Duration
+	lastID  int32
+}
+
+// openSource authenticates over an already dialed connection.
+func openSource(nc net.Conn, password string, authTimeout, timeout time.Duration) (*sourceConn, error) {
+	c := &sourceConn{conn: nc, timeout: timeout}
+	if err := nc.SetDeadline(time.Now().Add(authTimeout)); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("rcon: %w", err)
+	}
+	if err := c.write(c.nextID(), rcon.SERVERDATA_AUTH, password); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	for {
+		pid, typ, _, err := c.read()
+		if err != nil {
+			nc.Close()
+			return nil, err
+		}
+		// The server sends an empty RESPONSE_VALUE ahead of the verdict.
+		if typ != rcon.SERVERDATA_AUTH_RESPONSE {
+			continue
+		}
+		if pid == -1 {
+			nc.Close()
+			return nil, rcon.ErrAuthFailed
+		}
+		return c, nil
+	}
+}
+
+func (c *sourceConn) Execute(cmd string) (string, error) {
+	if cmd == "" {
+		return "", rcon.ErrCommandEmpty
+	}
+	if len(cmd) > rcon.MaxCommandLen {
+		return "", rcon.ErrCommandTooLong
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return "", fmt.Errorf("rcon: %w", err)
+	}
+
+	id, sentinel := c.nextID(), c.nextID()
+	if err := c.write(id, rcon.SERVERDATA_EXECCOMMAND, cmd); err != nil {
+		return "", err
+	}
+	if err := c.write(sentinel, rcon.SERVERDATA_RESPONSE_VALUE, ""); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for {
+		pid, _, body, err := c.read()
+		if err != nil {
+			return b.String(), err
+		}
+		switch pid {
+		case id:
+			b.Write(body)
+		case sentinel:
+			return b.String(), nil
+		}
+		// Anything else is left over from an earlier command, like the
+		// second packet srcds sends after each mirrored sentinel.
+	}
+}
+
+func (c *sourceConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *sourceConn) nextID() int32 {
+	c.lastID++
+	return c.lastID
+}
+
+func (c *sourceConn) write(id, typ int32, body string) error {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(len(body)+10))
+	binary.Write(&buf, binary.LittleEndian, id)
+	binary.Write(&buf, binary.LittleEndian, typ)
+	buf.WriteString(body)
+	buf.Write([]byte{0, 0})
+	if _, err := c.conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("rcon: write packet: %w", err)
+	}
+	return nil
+}
+
+func (c *sourceConn) read() (id, typ int32, body []byte, err error) {
+	var size int32
+	if err := binary.Read(c.conn, binary.LittleEndian, &size); err != nil {
+		return 0, 0, nil, fmt.Errorf("rcon: read packet size: %w", err)
+	}
+	if size < 10 || size > maxPacketSize {
+		return 0, 0, nil, fmt.Errorf("rcon: bad packet size %d", size)
+	}
+	pkt := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, pkt); err != nil {
+		return 0, 0, nil, fmt.Errorf("rcon: read packet: %w", err)
+	}
+	id = int32(binary.LittleEndian.Uint32(pkt))
+	typ = int32(binary.LittleEndian.Uint32(pkt[4:]))
+	return id, typ, pkt[8 : size-2], nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	statsSampleInterval = 5 * time.Second
+	maxStatsSamples     = 60 // per server; older samples fall off the graph
+)
+
+// resource graph
+
+// statsSeries is a ring of recent CPU and memory percentages for one
+// container, oldest first.
+type statsSeries struct {
+	cpu []float64
+	mem []float64
+}
+
+func (ss *statsSeries) add(cpu, mem float64) {
+	ss.cpu = append(ss.cpu, cpu)
+	ss.mem = append(ss.mem, mem)
+	if len(ss.cpu) > maxStatsSamples {
+		ss.cpu = ss.cpu[len(ss.cpu)-maxStatsSamples:]
+		ss.mem = ss.mem[len(ss.mem)-maxStatsSamples:]
+	}
+}
+
+type statsTickMsg struct{}
+
+// statsSampleMsg is one `docker stats` sample of the active container.
+type statsSampleMsg struct {
+	serverName string
+	cpu, mem   float64 // percent
+	err        error
+}
+
+func statsTick() tea.Cmd {
+	return tea.Tick(statsSampleInterval, func(time.Time) tea.Msg { return statsTickMsg{} })
+}
+
+// sampleStats takes one CPU and memory reading of s's container.
+func sampleStats(s serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), statsSampleInterval)
+		defer cancel()
+		out, err := dockerCommand(ctx, s, "stats", "--no-stream", "--format", "{{.CPUPerc}} {{.MemPerc}}", s.Container).Output()
+		if err != nil {
+			return statsSampleMsg{serverName: s.Name, err: err}
+		}
+		cpu, mem, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+		msg := statsSampleMsg{serverName: s.Name}
+		if msg.cpu, err = parsePercent(cpu); err == nil {
+			msg.mem, err = parsePercent(mem)
+		}
+		msg.err = err
+		return msg
+	}
+}
+
+func parsePercent(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected stats value %q", s)
+	}
+	return v, nil
+}
+
+// pollStats samples the active server's container, or just waits for the
+// next tick when it has none, so switching away stops the docker calls.
+// Sampling needs a container name; compose-only servers aren't graphed.
+func (m *model) pollStats() tea.Cmd {
+	s := m.activeServer()
+	if s == nil || s.Container == "" || m.noDocker {
+		return statsTick()
+	}
+	return sampleStats(*s)
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last width values as block characters, scaled so
+// that top fills a cell.
+func sparkline(vals []float64, width int, top float64) string {
+	if len(vals) > width {
+		vals = vals[len(vals)-width:]
+	}
+	var b strings.Builder
+	for _, v := range vals {
+		i := int(v / top * float64(len(sparkBlocks)-1))
+		i = max(0, min(i, len(sparkBlocks)-1))
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// resourceGraph renders the active container's CPU and memory sparklines
+// for the line above the log, or "" when there is nothing to graph.
+func (m *model) resourceGraph(width int) string {
+	ss := m.statHistory[m.activeName]
+	if ss == nil || len(ss.cpu) == 0 {
+		return ""
+	}
+	// "CPU " + graph + " 100.0%  " for each of the two series.
+	points := max(1, (width-2*13)/2)
+
+	// CPU can go past 100% on multi-core hosts; keep the scale honest.
+	top := 100.0
+	for _, v := range ss.cpu {
+		top = max(top, v)
+	}
+	cpu, mem := ss.cpu[len(ss.cpu)-1], ss.mem[len(ss.mem)-1]
+	return sparkLabelStyle.Render("CPU ") + sparkStyle.Render(sparkline(ss.cpu, points, top)) + sparkLabelStyle.Render(fmt.Sprintf(" %5.1f%%  MEM ", cpu)) +
+		sparkStyle.Render(sparkline(ss.mem, points, 100)) + sparkLabelStyle.Render(fmt.Sprintf(" %5.1f%%", mem))
+}
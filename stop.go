@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultStopGrace = 10 * time.Second
+
+// graceful stop
+
+// commandList is one command or a list of them in the config, e.g.
+// `stop_command: stop` or `stop_command: [save-all, stop]`.
+type commandList []string
+
+func (c *commandList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*c = commandList{n.Value}
+		return nil
+	}
+	var cmds []string
+	if err := n.Decode(&cmds); err != nil {
+		return err
+	}
+	*c = cmds
+	return nil
+}
+
+func (c *commandList) UnmarshalTOML(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*c = commandList{v}
+	case []interface{}:
+		cmds := make(commandList, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected a command string, got %T", e)
+			}
+			cmds = append(cmds, s)
+		}
+		*c = cmds
+	default:
+		return fmt.Errorf("expected a command or a list of commands, got %T", v)
+	}
+	return nil
+}
+
+func (s serverConfig) stopGrace() time.Duration {
+	if s.StopGrace > 0 {
+		return s.StopGrace
+	}
+	return defaultStopGrace
+}
+
+// stopCommandsMsg reports how the stop_command sequence went. It stops at
+// the first failure, which is then the last result.
+type stopCommandsMsg struct {
+	serverName string
+	results    []rconResultMsg
+}
+
+// runStopCommands sends s's stop_command sequence in order. Cancelling ctx
+// abandons the rest of it.
+func runStopCommands(ctx context.Context, pool *connPool, s serverConfig) tea.Cmd {
+	return func() tea.Msg {
+		var results []rconResultMsg
+		for _, cmd := range s.StopCommand {
+			res := sendRCONCmd(ctx, pool, s, cmd)().(rconResultMsg)
+			results = append(results, res)
+			if res.err != nil {
+				break
+			}
+		}
+		return stopCommandsMsg{serverName: s.Name, results: results}
+	}
+}
+
+// stopContainer stops s's container, first letting the game save and shut
+// down over RCON when it has a stop_command.
+func (m *model) stopContainer(s serverConfig) tea.Cmd {
+	if len(s.StopCommand) == 0 {
+		return m.dockerStop(s)
+	}
+	m.pushLogFor(s.Name, logDocker, fmt.Sprintf("[%s] 🛑 Graceful stop: sending %d stop command(s)", s.Name, len(s.StopCommand)))
+	m.setStatus("Sending stop commands...")
+	return tea.Batch(m.sending(1), runStopCommands(m.sendCtx, m.pool, s))
+}
+
+// stopCommandsDone logs the stop_command results, then waits out the grace
+// period before the docker stop, or asks whether to force it if one failed.
+func (m *model) stopCommandsDone(msg stopCommandsMsg) tea.Cmd {
+	s := m.serverByName(msg.serverName)
+	if s == nil {
+		return nil
+	}
+
+	var failed error
+	for _, res := range msg.results {
+		m.logDial(res)
+		m.record(rconEvent(res))
+		m.pushLogFor(s.Name, logCommand, fmt.Sprintf("[%s] > %s", s.Name, res.cmd))
+		if errors.Is(res.err, context.Canceled) {
+			// The user called it off, so don't offer to stop regardless.
+			m.pushLogFor(s.Name, logWarn, fmt.Sprintf("[%s] 🚫 [%s] Cancelled", s.Name, res.cmd))
+			m.pushLogFor(s.Name, logInfo, fmt.Sprintf("[%s] 🛑 Graceful stop cancelled", s.Name))
+			m.setStatus("Cancelled")
+			return nil
+		}
+		if res.err != nil {
+			m.pushLogFor(s.Name, logError, fmt.Sprintf("[%s] ⚠️ [%s] ERROR: %v", s.Name, res.cmd, res.err))
+			failed = res.err
+			continue
+		}
+		m.pushLogFor(s.Name, logResponse, responseText(s.Name, res.cmd, res.output))
+	}
+
+	if failed != nil {
+		srv := *s
+		m.setStatus("Stop command failed")
+		m.confirm(fmt.Sprintf("[%s] RCON stop command failed. Stop the container anyway?", s.Name), func(m *model) tea.Cmd {
+			return m.dockerStop(srv)
+		}, func(m *model) {
+			m.pushLogFor(srv.Name, logInfo, fmt.Sprintf("[%s] 🛑 Graceful stop cancelled", srv.Name))
+		})
+		return nil
+	}
+
+	grace := s.stopGrace()
+	m.pushLogFor(s.Name, logDocker, fmt.Sprintf("[%s] ⏳ Waiting %s before stopping the container", s.Name, grace))
+	m.setStatus(fmt.Sprintf("Waiting %s...", grace))
+	return tea.Tick(grace, func(time.Time) tea.Msg { return stopGraceMsg{serverName: s.Name} })
+}
+
+// stopGraceMsg fires once a graceful stop's grace period is over.
+type stopGraceMsg struct {
+	serverName string
+}
+
+func (m *model) dockerStop(s serverConfig) tea.Cmd {
+	m.pushLogFor(s.Name, logDocker, fmt.Sprintf("[%s] 🐳 Stopping container: %s", s.Name, s.containerLabel()))
+	m.setStatus("Stopping container...")
+	return m.runDocker(s, "stop")
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// command templates
+
+// templateMap is named command templates, e.g. kick: "kick {player} {reason}".
+type templateMap map[string]string
+
+// templateVar matches a {name} placeholder. Only identifiers count, so JSON
+// text components like {"text":"hi"} pass through untouched.
+var templateVar = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// templateVars returns the placeholders in tmpl in order of first use.
+func templateVars(tmpl string) []string {
+	var vars []string
+	seen := make(map[string]bool)
+	for _, m := range templateVar.FindAllStringSubmatch(tmpl, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			vars = append(vars, m[1])
+		}
+	}
+	return vars
+}
+
+// fillTemplate replaces every placeholder in tmpl with its value.
+func fillTemplate(tmpl string, values map[string]string) string {
+	return templateVar.ReplaceAllStringFunc(tmpl, func(p string) string {
+		return values[p[1:len(p)-1]]
+	})
+}
+
+// templateMenu is the open template picker for one server. Once a template
+// is chosen, form holds the fields being filled in for it.
+type templateMenu struct {
+	serverName string
+	names      []string
+	tmpls      templateMap
+	cursor     int
+	form       *templateForm
+}
+
+// templateForm is one input per placeholder of the chosen template.
+type templateForm struct {
+	name   string
+	tmpl   string
+	vars   []string
+	inputs []textinput.Model
+	focus  int
+	err    string
+}
+
+// openTemplates shows the active server's templates, global ones included,
+// or says why there are none to show.
+func (m *model) openTemplates() {
+	s := m.activeServer()
+	if s == nil {
+		return
+	}
+	tmpls := make(templateMap, len(m.templates)+len(s.Templates))
+	for name, t := range m.templates {
+		tmpls[name] = t
+	}
+	for name, t := range s.Templates {
+		tmpls[name] = t
+	}
+	if len(tmpls) == 0 {
+		m.setStatus("No templates configured for this server")
+		return
+	}
+	names := make([]string, 0, len(tmpls))
+	for name := range tmpls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	m.tmplMenu = &templateMenu{serverName: s.Name, names: names, tmpls: tmpls}
+}
+
+// newTemplateForm builds the form for the named template, the first field
+// focused.
+func newTemplateForm(name, tmpl string) *templateForm {
+	f := &templateForm{name: name, tmpl: tmpl, vars: templateVars(tmpl)}
+	width := 0
+	for _, v := range f.vars {
+		width = max(width, len(v))
+	}
+	for _, v := range f.vars {
+		ti := textinput.New()
+		ti.Prompt = fmt.Sprintf("%-*s  ", width, v)
+		ti.PromptStyle = helpKeyStyle
+		f.inputs = append(f.inputs, ti)
+	}
+	f.inputs[0].Focus()
+	return f
+}
+
+func (f *templateForm) move(delta int) {
+	f.inputs[f.focus].Blur()
+	f.focus = (f.focus + delta + len(f.inputs)) % len(f.inputs)
+	f.inputs[f.focus].Focus()
+}
+
+// expand fills the template from the form, or returns "" and marks the
+// form when a field is still empty.
+func (f *templateForm) expand() string {
+	values := make(map[string]string, len(f.vars))
+	for i, v := range f.vars {
+		val := strings.TrimSpace(f.inputs[i].Value())
+		if val == "" {
+			f.err = v + " is empty"
+			f.move(i - f.focus)
+			return ""
+		}
+		values[v] = val
+	}
+	return fillTemplate(f.tmpl, values)
+}
+
+// updateTemplates handles keys while the picker or its form is open. In
+// the picker, Up and Down choose and Enter opens the form, or sends a
+// template that has no placeholders. In the form, Tab and Up/Down move
+// between fields and Enter on the last one sends. Esc steps back out.
+func (m model) updateTemplates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.tmplMenu
+	if f := t.form; f != nil {
+		switch {
+		case msg.String() == "esc":
+			t.form = nil
+		case msg.Type != tea.KeyRunes && m.keys.matches(msg, "quit"):
+			m.tmplMenu = nil
+		case msg.String() == "up", msg.String() == "shift+tab":
+			f.move(-1)
+		case msg.String() == "down", msg.String() == "tab":
+			f.move(1)
+		case msg.String() == "enter" && f.focus < len(f.inputs)-1:
+			f.move(1)
+		case msg.String() == "enter":
+			if cmd := f.expand(); cmd != "" {
+				m.tmplMenu = nil
+				return m.submit(cmd)
+			}
+		default:
+			f.err = ""
+			var cmd tea.Cmd
+			f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch {
+	case msg.String() == "up", msg.String() == "k", msg.String() == "shift+tab":
+		t.cursor = (t.cursor + len(t.names) - 1) % len(t.names)
+	case msg.String() == "down", msg.String() == "j", msg.String() == "tab":
+		t.cursor = (t.cursor + 1) % len(t.names)
+	case msg.String() == "enter":
+		name := t.names[t.cursor]
+		tmpl := t.tmpls[name]
+		if len(templateVars(tmpl)) == 0 {
+			m.tmplMenu = nil
+			return m.submit(tmpl)
+		}
+		t.form = newTemplateForm(name, tmpl)
+	case msg.String() == "esc", m.keys.matches(msg, "templates"), m.keys.matches(msg, "quit"):
+		m.tmplMenu = nil
+	}
+	return m, nil
+}
+
+// templatesView renders the open picker, or the form for the chosen
+// template, centred in the window.
+func (m model) templatesView() string {
+	t := m.tmplMenu
+	var b strings.Builder
+	if f := t.form; f != nil {
+		b.WriteString(helpTitleStyle.Render(fmt.Sprintf("%s · %s", f.name, t.serverName)))
+		b.WriteString("\n" + helpDescStyle.Render(f.tmpl) + "\n")
+		for _, in := range f.inputs {
+			b.WriteString("\n" + in.View())
+		}
+		if f.err != "" {
+			b.WriteString("\n\n" + logStyles[logError].Render(f.err))
+		}
+		b.WriteString("\n\n" + helpDescStyle.Render("Tab next field, Enter on the last sends, Esc back"))
+	} else {
+		b.WriteString(helpTitleStyle.Render(fmt.Sprintf("Templates · %s", t.serverName)))
+		b.WriteString("\n")
+		for i, name := range t.names {
+			line := fmt.Sprintf("%s  %s", name, t.tmpls[name])
+			if i == t.cursor {
+				fmt.Fprintf(&b, "\n%s", activeCandidateStyle.Render("> "+line))
+			} else {
+				fmt.Fprintf(&b, "\n%s", candidateStyle.Render("  "+line))
+			}
+		}
+		b.WriteString("\n\n" + helpDescStyle.Render("Up/Down choose, Enter fill in, Esc close"))
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpBoxStyle.Render(b.String()))
+}
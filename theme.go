@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// themes
+
+// theme assigns a color to each role the UI draws with. Colors are ANSI
+// numbers ("9") or hex ("#ff5f87").
+type theme struct {
+	Text    lipgloss.Color // help descriptions
+	Status  lipgloss.Color // status bar, footer, timestamps and other secondary text
+	Error   lipgloss.Color
+	Success lipgloss.Color // responses, online servers, running containers
+	Warning lipgloss.Color
+	Docker  lipgloss.Color // docker output and the resource graph
+	Prompt  lipgloss.Color // yes/no questions
+	Border  lipgloss.Color // help box, section titles
+	Accent  lipgloss.Color // highlighted keys and completion candidates
+}
+
+var builtinThemes = map[string]theme{
+	"dark": {
+		Text:    "250",
+		Status:  "8",
+		Error:   "9",
+		Success: "10",
+		Warning: "11",
+		Docker:  "14",
+		Prompt:  "11",
+		Border:  "63",
+		Accent:  "212",
+	},
+	"light": {
+		Text:    "236",
+		Status:  "244",
+		Error:   "160",
+		Success: "28",
+		Warning: "130",
+		Docker:  "30",
+		Prompt:  "166",
+		Border:  "61",
+		Accent:  "162",
+	},
+}
+
+// roles maps the config's role names to the theme's colors.
+func (t *theme) roles() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"text":    &t.Text,
+		"status":  &t.Status,
+		"error":   &t.Error,
+		"success": &t.Success,
+		"warning": &t.Warning,
+		"docker":  &t.Docker,
+		"prompt":  &t.Prompt,
+		"border":  &t.Border,
+		"accent":  &t.Accent,
+	}
+}
+
+// themeConfig is the theme setting: either a built-in theme's name, or a
+// block of role colors on top of a base theme, e.g.
+//
+//	theme:
+//	  base: light
+//	  error: "#d70000"
+type themeConfig struct {
+	Base   string            // built-in theme, defaults to dark
+	Colors map[string]string // role name -> color
+}
+
+func (tc *themeConfig) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*tc = themeConfig{Base: n.Value}
+		return nil
+	}
+	var block map[string]string
+	if err := n.Decode(&block); err != nil {
+		return err
+	}
+	tc.set(block)
+	return nil
+}
+
+func (tc *themeConfig) UnmarshalTOML(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*tc = themeConfig{Base: v}
+	case map[string]interface{}:
+		block := make(map[string]string, len(v))
+		for k, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("theme: expected a color string for %s, got %T", k, e)
+			}
+			block[k] = s
+		}
+		tc.set(block)
+	default:
+		return fmt.Errorf("expected a theme name or a table of colors, got %T", v)
+	}
+	return nil
+}
+
+func (tc *themeConfig) set(block map[string]string) {
+	*tc = themeConfig{Base: block["base"], Colors: block}
+	delete(tc.Colors, "base")
+}
+
+// resolve builds the configured theme.
+func (tc themeConfig) resolve() (theme, error) {
+	base := tc.Base
+	if base == "" {
+		base = "dark"
+	}
+	t, ok := builtinThemes[strings.ToLower(base)]
+	if !ok {
+		return theme{}, fmt.Errorf("theme: unknown theme %q, expected dark or light", base)
+	}
+	roles := t.roles()
+	for name, color := range tc.Colors {
+		c, ok := roles[name]
+		if !ok {
+			names := make([]string, 0, len(roles))
+			for n := range roles {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return theme{}, fmt.Errorf("theme: unknown color role %q, expected one of %s", name, strings.Join(names, ", "))
+		}
+		*c = lipgloss.Color(color)
+	}
+	return t, nil
+}
+
+// styles
+
+// activeTheme is the theme the styles below were built from.
+var activeTheme theme
+
+var (
+	statusStyle    lipgloss.Style
+	timestampStyle lipgloss.Style
+	logStyles      map[logKind]lipgloss.Style
+	tabStyle       lipgloss.Style
+	activeTabStyle lipgloss.Style
+
+	candidateStyle       lipgloss.Style
+	activeCandidateStyle lipgloss.Style
+	confirmStyle         lipgloss.Style
+	popupStyle           lipgloss.Style
+
+	helpBoxStyle   lipgloss.Style
+	helpTitleStyle lipgloss.Style
+	helpKeyStyle   lipgloss.Style
+	helpDescStyle  lipgloss.Style
+
+	panelTitleStyle  lipgloss.Style
+	groupHeaderStyle lipgloss.Style
+
+	onlineDot, offlineDot, unknownDot       string
+	backupDot                               string // reached, but only through a backup address
+	connectedGlyph, idleGlyph, noRouteGlyph string
+
+	sparkStyle      lipgloss.Style
+	sparkLabelStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(builtinThemes["dark"])
+}
+
+// applyTheme rebuilds every style from t.
+func applyTheme(t theme) {
+	activeTheme = t
+	fg := func(c lipgloss.Color) lipgloss.Style { return lipgloss.NewStyle().Foreground(c) }
+
+	statusStyle = fg(t.Status)
+	timestampStyle = fg(t.Status)
+	logStyles = map[logKind]lipgloss.Style{
+		logInfo:     lipgloss.NewStyle(),
+		logCommand:  lipgloss.NewStyle().Bold(true),
+		logResponse: fg(t.Success),
+		logDocker:   fg(t.Docker),
+		logWarn:     fg(t.Warning),
+		logError:    fg(t.Error),
+		logDebug:    fg(t.Status),
+	}
+	tabStyle = fg(t.Status).Padding(0, 1)
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Bold(true).Reverse(true)
+
+	candidateStyle = fg(t.Status)
+	activeCandidateStyle = fg(t.Accent).Bold(true)
+	confirmStyle = fg(t.Prompt).Bold(true)
+	popupStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(t.Border).Padding(0, 1)
+
+	helpBoxStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(t.Border).Padding(1, 2)
+	helpTitleStyle = fg(t.Border).Bold(true)
+	helpKeyStyle = fg(t.Accent)
+	helpDescStyle = fg(t.Text)
+
+	panelTitleStyle = fg(t.Border).Bold(true)
+	groupHeaderStyle = fg(t.Border).Bold(true)
+
+	onlineDot = fg(t.Success).Render("●")
+	offlineDot = fg(t.Error).Render("●")
+	unknownDot = fg(t.Status).Render("●")
+	backupDot = fg(t.Warning).Render("●")
+	connectedGlyph = fg(t.Success).Render("⇄")
+	idleGlyph = fg(t.Status).Render("·")
+	noRouteGlyph = fg(t.Error).Render("×")
+
+	sparkStyle = fg(t.Docker)
+	sparkLabelStyle = fg(t.Status)
+}
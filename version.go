@@ -0,0 +1,21 @@
+package main
+
+import "runtime/debug"
+
+// version
+
+// version is set at build time:
+//
+//	go build -ldflags "-X main.version=v1.2.0"
+var version = "dev"
+
+// versionString is the build's version, falling back to the module version
+// `go install` records when none was injected.
+func versionString() string {
+	if version == "dev" {
+		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			return bi.Main.Version
+		}
+	}
+	return version
+}
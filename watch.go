@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// watched output
+
+// watchConfig is one watch entry: a pattern, written like a highlights
+// pattern, and how to call attention to log lines that match it.
+type watchConfig struct {
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	Bell    bool   `yaml:"bell,omitempty" toml:"bell,omitempty"`     // ring the terminal bell
+	Status  bool   `yaml:"status,omitempty" toml:"status,omitempty"` // show the line in the status bar until the next key press
+}
+
+type watchRule struct {
+	re           *regexp.Regexp
+	bell, status bool
+}
+
+func compileWatches(entries []watchConfig) ([]watchRule, error) {
+	rules := make([]watchRule, 0, len(entries))
+	for i, e := range entries {
+		if e.Pattern == "" {
+			return nil, fmt.Errorf("watch: entry %d has no pattern", i+1)
+		}
+		if !e.Bell && !e.Status {
+			return nil, fmt.Errorf("watch: %q sets neither bell nor status", e.Pattern)
+		}
+		re, err := compilePattern(e.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("watch: %w", err)
+		}
+		rules = append(rules, watchRule{re: re, bell: e.Bell, status: e.Status})
+	}
+	return rules, nil
+}
+
+// checkWatches raises the alerts of every watch the plain text of a new log
+// line from server matches.
+func (m *model) checkWatches(server, text string) {
+	bell := false
+	for _, w := range m.watches {
+		if !w.re.MatchString(text) {
+			continue
+		}
+		bell = bell || w.bell
+		if w.status {
+			m.watchAlert = fmt.Sprintf("🔔 %s: %s", server, text)
+		}
+	}
+	if bell {
+		// Written beside the renderer's output; a lone BEL doesn't disturb it.
+		fmt.Fprint(os.Stderr, "\a")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/gorcon/rcon"
+	"github.com/gorilla/websocket"
+)
+
+// websocket rcon
+
+// webRCONConn is a Rust WebRCON session: JSON frames over a websocket at
+// ws://<address>/<password>. It satisfies rconClient like a TCP rcon.Conn.
+type webRCONConn struct {
+	ws      *websocket.Conn
+	timeout time.Duration
+	nextID  int
+}
+
+type webRCONPacket struct {
+	Identifier int    `json:"Identifier"`
+	Message    string `json:"Message"`
+	Name       string `json:"Name,omitempty"`
+	Type       string `json:"Type,omitempty"`
+}
+
+// dialWebRCON connects and authenticates in one step; the server refuses
+// the websocket upgrade when the password is wrong. execTimeout bounds each
+// Execute.
+func dialWebRCON(address, password string, timeout, execTimeout time.Duration, netDial func(network, addr string) (net.Conn, error)) (*webRCONConn, error) {
+	d := websocket.Dialer{HandshakeTimeout: timeout, NetDial: netDial}
+	u := url.URL{Scheme: "ws", Host: address, Path: "/" + password}
+	ws, _, err := d.Dial(u.String(), nil)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) {
+			return nil, fmt.Errorf("rcon: %w", rcon.ErrAuthFailed)
+		}
+		return nil, err
+	}
+	return &webRCONConn{ws: ws, timeout: execTimeout}, nil
+}
+
+// Execute sends cmd and waits for the reply carrying the same identifier.
+// Console output the server broadcasts in the meantime is skipped.
+func (c *webRCONConn) Execute(cmd string) (string, error) {
+	c.nextID++
+	id := c.nextID
+
+	c.ws.SetWriteDeadline(time.Now().Add(c.timeout))
+	if err := c.ws.WriteJSON(webRCONPacket{Identifier: id, Message: cmd, Name: "bubblecon"}); err != nil {
+		return "", err
+	}
+
+	c.ws.SetReadDeadline(time.Now().Add(c.timeout))
+	for {
+		var p webRCONPacket
+		if err := c.ws.ReadJSON(&p); err != nil {
+			return "", err
+		}
+		if p.Identifier == id {
+			return p.Message, nil
+		}
+	}
+}
+
+func (c *webRCONConn) Close() error {
+	return c.ws.Close()
+}